@@ -20,13 +20,23 @@ type OauthConfig struct {
 	FpfssTokenEndpoint string `json:"fpfss_token_endpoint"`
 }
 
+const (
+	FpfssSourceRemote = "remote"
+	FpfssSourceFile   = "file"
+)
+
 type AppConfig struct {
 	Name                         string
 	Port                         int64
 	FpfssApiUrl                  string
+	FpfssSource                  string
+	FpfssFilePath                string
 	Version                      string
 	OauthConfig                  *OauthConfig
 	SessionExpirationSeconds     int64
+	SessionPurgeSeconds          int64
+	SessionSecretKey             string
+	RoleSyncSeconds              int64
 	PostgresUser                 string
 	PostgresPassword             string
 	PostgresHost                 string
@@ -36,13 +46,18 @@ type AppConfig struct {
 	SecurecookieBlockKeyPrevious string
 	SecurecookieHashKeyCurrent   string
 	SecurecookieBlockKeyCurrent  string
+	GotdMaxOpenSuggestions       int64
+	GameCacheStaleSeconds        int64
+	GameCacheRefreshSeconds      int64
+	GameCacheRefreshBatchSize    int64
 }
 
 func GetConfig() (*AppConfig, error) {
-	return &AppConfig{
+	conf := &AppConfig{
 		Name:        EnvString("APP_NAME"),
 		Port:        EnvInt("APP_PORT"),
 		FpfssApiUrl: EnvString("FPFSS_API_URL"),
+		FpfssSource: EnvStringDefault("FPFSS_SOURCE", FpfssSourceRemote),
 		OauthConfig: &OauthConfig{
 			ClientID:           EnvString("OAUTH_CLIENT_ID"),
 			ClientSecret:       EnvString("OAUTH_CLIENT_SECRET"),
@@ -57,6 +72,9 @@ func GetConfig() (*AppConfig, error) {
 			FpfssTokenEndpoint: EnvString("OAUTH_FPFSS_TOKEN_ENDPOINT"),
 		},
 		SessionExpirationSeconds:     EnvInt("SESSION_EXPIRATION_SECONDS"),
+		SessionPurgeSeconds:          EnvIntDefault("SESSION_PURGE_SECONDS", 3600),
+		SessionSecretKey:             EnvString("SESSION_SECRET_KEY"),
+		RoleSyncSeconds:              EnvIntDefault("ROLE_SYNC_SECONDS", 3600),
 		PostgresUser:                 EnvString("POSTGRES_USER"),
 		PostgresPassword:             EnvString("POSTGRES_PASSWORD"),
 		PostgresHost:                 EnvString("POSTGRES_HOST"),
@@ -66,7 +84,41 @@ func GetConfig() (*AppConfig, error) {
 		SecurecookieBlockKeyPrevious: EnvString("SECURECOOKIE_BLOCK_KEY_PREVIOUS"),
 		SecurecookieHashKeyCurrent:   EnvString("SECURECOOKIE_HASH_KEY_CURRENT"),
 		SecurecookieBlockKeyCurrent:  EnvString("SECURECOOKIE_BLOCK_KEY_CURRENT"),
-	}, nil
+		GotdMaxOpenSuggestions:       EnvIntDefault("GOTD_MAX_OPEN_SUGGESTIONS", 5),
+		GameCacheStaleSeconds:        EnvIntDefault("GAME_CACHE_STALE_SECONDS", 86400),
+		GameCacheRefreshSeconds:      EnvIntDefault("GAME_CACHE_REFRESH_SECONDS", 600),
+		GameCacheRefreshBatchSize:    EnvIntDefault("GAME_CACHE_REFRESH_BATCH_SIZE", 100),
+	}
+
+	switch conf.FpfssSource {
+	case FpfssSourceRemote:
+	case FpfssSourceFile:
+		conf.FpfssFilePath = EnvString("FPFSS_FILE_PATH")
+	default:
+		return nil, fmt.Errorf("invalid value of env variable 'FPFSS_SOURCE'")
+	}
+
+	// Session secrets are HMAC-SHA256 keyed, a shorter key weakens them
+	if len(conf.SessionSecretKey) < 32 {
+		return nil, fmt.Errorf("env variable 'SESSION_SECRET_KEY' must be at least 32 bytes")
+	}
+	if conf.SessionPurgeSeconds <= 0 {
+		return nil, fmt.Errorf("env variable 'SESSION_PURGE_SECONDS' must be greater than 0")
+	}
+	if conf.RoleSyncSeconds <= 0 {
+		return nil, fmt.Errorf("env variable 'ROLE_SYNC_SECONDS' must be greater than 0")
+	}
+	if conf.GameCacheStaleSeconds <= 0 {
+		return nil, fmt.Errorf("env variable 'GAME_CACHE_STALE_SECONDS' must be greater than 0")
+	}
+	if conf.GameCacheRefreshSeconds <= 0 {
+		return nil, fmt.Errorf("env variable 'GAME_CACHE_REFRESH_SECONDS' must be greater than 0")
+	}
+	if conf.GameCacheRefreshBatchSize <= 0 {
+		return nil, fmt.Errorf("env variable 'GAME_CACHE_REFRESH_BATCH_SIZE' must be greater than 0")
+	}
+
+	return conf, nil
 }
 
 func EnvString(name string) string {
@@ -77,6 +129,14 @@ func EnvString(name string) string {
 	return s
 }
 
+// EnvStringDefault is EnvString for optional variables, returning def when the variable is not set
+func EnvStringDefault(name string, def string) string {
+	if os.Getenv(name) == "" {
+		return def
+	}
+	return EnvString(name)
+}
+
 func EnvInt(name string) int64 {
 	s := os.Getenv(name)
 	if s == "" {
@@ -89,6 +149,14 @@ func EnvInt(name string) int64 {
 	return i
 }
 
+// EnvIntDefault is EnvInt for optional variables, returning def when the variable is not set
+func EnvIntDefault(name string, def int64) int64 {
+	if os.Getenv(name) == "" {
+		return def
+	}
+	return EnvInt(name)
+}
+
 func EnvBool(name string) bool {
 	s := os.Getenv(name)
 	if s == "" {
@@ -1,6 +1,9 @@
 package constants
 
-import "github.com/FlashpointProject/CommunityWebsite/types"
+import (
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
 
 const (
 	RoleAdministrator = "441043545735036929"
@@ -18,6 +21,36 @@ func StaffRoles() []string {
 	}
 }
 
+// ApiTokenPrefix starts every personal API token, telling them apart from the legacy bearer session token
+const ApiTokenPrefix = "fpcomm_"
+
+const (
+	ScopeProfileRead    = "profile:read"
+	ScopePlaylistsRead  = "playlists:read"
+	ScopePlaylistsWrite = "playlists:write"
+	ScopeGotdSuggest    = "gotd:suggest"
+)
+
+// ApiTokenScopes lists the scopes a personal API token can be granted
+func ApiTokenScopes() []string {
+	return []string{
+		ScopeProfileRead,
+		ScopePlaylistsRead,
+		ScopePlaylistsWrite,
+		ScopeGotdSuggest,
+	}
+}
+
+// IsStaff checks a user's role IDs against the staff roles
+func IsStaff(roles []string) bool {
+	for _, r := range StaffRoles() {
+		if utils.StringInSlice(r, roles) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsModerator(roles []*types.DiscordRole) bool {
 	for _, r := range roles {
 		if r.ID == RoleAdministrator {
@@ -189,6 +189,9 @@ func (sb *SqlBuilder) ArgumentsCount() []interface{} {
 	return allArgs
 }
 
+// GameCacheColumns are the game_cache columns read by ReadGame, in order
+const GameCacheColumns = "id, title, series, developer, publisher, release_date, play_mode, language, original_description, platform_name, extreme, filter_groups, removed, updated_at"
+
 func ReadGame(row pgx.Row) (*types.CachedGame, error) {
 	var id string
 	var title string
@@ -200,8 +203,11 @@ func ReadGame(row pgx.Row) (*types.CachedGame, error) {
 	var language []string
 	var originalDescription string
 	var platformName string
+	var extreme bool
+	var filterGroups []string
+	var removed bool
 	var updatedAt time.Time
-	err := row.Scan(&id, &title, &series, &developer, &publisher, &releaseDate, &playMode, &language, &originalDescription, &platformName, &updatedAt)
+	err := row.Scan(&id, &title, &series, &developer, &publisher, &releaseDate, &playMode, &language, &originalDescription, &platformName, &extreme, &filterGroups, &removed, &updatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -222,6 +228,9 @@ func ReadGame(row pgx.Row) (*types.CachedGame, error) {
 		Language:            language,
 		OriginalDescription: originalDescription,
 		Platform:            platformName,
+		Extreme:             extreme,
+		FilterGroups:        filterGroups,
+		Removed:             removed,
 		UpdatedAt:           updatedAt,
 	}, nil
 }
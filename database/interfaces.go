@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/jackc/pgx/v5"
@@ -9,21 +10,71 @@ import (
 
 type PGDAL interface {
 	NewSession(ctx context.Context) (PGDBSession, error)
-	StoreSession(dbs PGDBSession, secret string, uid string, durationSeconds int64, ipAddr string) error
-	GetSessionAuthInfo(dbs PGDBSession, secret string) (*types.SessionInfo, bool, error)
+	StoreSession(dbs PGDBSession, secretHash string, uid string, durationSeconds int64, ipAddr string, userAgent string) error
+	GetSessionAuthInfo(dbs PGDBSession, secretHash string) (*types.SessionInfo, bool, error)
+	RotateSession(dbs PGDBSession, id int64, oldSecretHash string, newSecretHash string) (bool, error)
+	GetUserSessions(dbs PGDBSession, uid string) ([]*types.SessionInfo, error)
+	DeleteSession(dbs PGDBSession, uid string, id int64) (bool, error)
+	DeleteSessionBySecret(dbs PGDBSession, secretHash string) error
+	DeleteUserSessions(dbs PGDBSession, uid string) (int64, error)
+	PurgeExpiredSessions(dbs PGDBSession) (int64, error)
+	SaveOAuthState(dbs PGDBSession, state *types.OAuthState, ttl time.Duration) error
+	ConsumeOAuthState(dbs PGDBSession, nonce string) (*types.OAuthState, error)
+	PurgeExpiredOAuthStates(dbs PGDBSession) (int64, error)
+	GetApiTokenAuthInfo(dbs PGDBSession, tokenHash string) (*types.SessionInfo, bool, error)
+	TouchApiToken(dbs PGDBSession, id int64) error
+	GetApiTokens(dbs PGDBSession, uid string) ([]*types.ApiToken, error)
+	CountApiTokens(dbs PGDBSession, uid string) (int64, error)
+	SaveApiToken(dbs PGDBSession, uid string, tokenHash string, token *types.ApiToken) error
+	DeleteApiToken(dbs PGDBSession, uid string, id int64) (bool, error)
+	DeleteUserApiTokens(dbs PGDBSession, uid string) (int64, error)
 
 	GetRoles(dbs PGDBSession) ([]*types.DiscordRole, error)
 	SaveRoles(dbs PGDBSession, roles []*types.DiscordRole) error
 	SaveUser(dbs PGDBSession, uid string, name string, avatarURL string, roles []string) error
 	GetUser(dbs PGDBSession, uid string) (*types.UserProfile, error)
+	UpdateUserRoles(dbs PGDBSession, uid string, roles []string) (bool, error)
+	GetActiveUserIDs(dbs PGDBSession) ([]string, error)
+	GetUserFilterPreferences(dbs PGDBSession, uid string) (*types.UserFilterPreferences, error)
+	SaveUserFilterPreferences(dbs PGDBSession, uid string, hiddenFilterGroups []int64) error
 
 	SearchPlaylists(dbs PGDBSession, query *types.PlaylistSearchQuery) ([]*types.Playlist, int64, error)
 	GetPlaylist(dbs PGDBSession, id int64) (*types.Playlist, error)
 	SavePlaylist(dbs PGDBSession, uid string, playlist *types.Playlist, fpfss types.IFpfss) error
 	DeletePlaylist(dbs PGDBSession, id int64) error
+	CreatePlaylistRevision(dbs PGDBSession, uid string, playlist *types.Playlist) error
+	GetPlaylistRevisions(dbs PGDBSession, playlistID int64) ([]*types.PlaylistRevisionInfo, error)
+	GetPlaylistRevision(dbs PGDBSession, playlistID int64, revision int64) (*types.PlaylistRevision, error)
+	GetPlaylistSearchFacets(dbs PGDBSession, query *types.PlaylistSearchQuery) (*types.PlaylistSearchFacets, error)
+	RefreshGamePlaylistsSearch(dbs PGDBSession, gameIDs []string) (int64, error)
+	GetPopularPlaylistForks(dbs PGDBSession, id int64, limit int64) ([]*types.PlaylistInfo, error)
+	LikePlaylist(dbs PGDBSession, id int64, uid string) (bool, error)
+	UnlikePlaylist(dbs PGDBSession, id int64, uid string) (bool, error)
+	BookmarkPlaylist(dbs PGDBSession, id int64, uid string) error
+	UnbookmarkPlaylist(dbs PGDBSession, id int64, uid string) error
+	GetPlaylistReactions(dbs PGDBSession, id int64, uid string) (*types.PlaylistReactions, error)
+	GetPlaylistCollaborators(dbs PGDBSession, playlistID int64) ([]*types.PlaylistCollaborator, error)
+	GetUserPlaylistInvites(dbs PGDBSession, uid string) ([]*types.PlaylistCollaborator, error)
+	GetPlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) (*types.PlaylistCollaborator, error)
+	SavePlaylistCollaborator(dbs PGDBSession, collaborator *types.PlaylistCollaborator) error
+	AcceptPlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) error
+	DeletePlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) error
 
 	GetGames(dbs PGDBSession, ids []string, fpfss types.IFpfss) ([]*types.CachedGame, error)
+	SearchGames(dbs PGDBSession, query *types.GameSearchQuery) ([]*types.CachedGame, int64, error)
+	AutocompleteGames(dbs PGDBSession, text string, filter *types.ContentFilter, limit int64) ([]*types.CachedGame, error)
+	GetGamePlaylists(dbs PGDBSession, gameID string, filter *types.ContentFilter, limit int64) ([]*types.GamePlaylistEntry, int64, error)
+	GetRelatedGames(dbs PGDBSession, gameID string, filter *types.ContentFilter, limit int64) ([]*types.RelatedGame, error)
 	GetGame(dbs PGDBSession, id string, fpfss types.IFpfss) (*types.CachedGame, error)
+	CacheGames(dbs PGDBSession, fpfssGames []*types.FpfssGame) error
+	GetStaleGameIDs(dbs PGDBSession, before time.Time, limit int64) ([]string, error)
+	MarkGamesRemoved(dbs PGDBSession, ids []string) error
+
+	GetFilterGroups(dbs PGDBSession) ([]*types.FilterGroup, error)
+	GetFilterGroup(dbs PGDBSession, id int64) (*types.FilterGroup, error)
+	SaveFilterGroup(dbs PGDBSession, group *types.FilterGroup) error
+	DeleteFilterGroup(dbs PGDBSession, id int64) error
+	RecomputeFilterGroups(dbs PGDBSession, tags []string, groupNames []string) (int64, int64, error)
 
 	SearchNewsPosts(dbs PGDBSession, query *types.NewsPostSearchQuery) ([]*types.NewsPost, int64, error)
 	GetNewsPost(dbs PGDBSession, id int64) (*types.NewsPost, error)
@@ -34,11 +85,18 @@ type PGDAL interface {
 
 	SearchGotdSuggestions(dbs PGDBSession, query *types.GotdSuggestionsSearchQuery, fpfss types.IFpfss) ([]*types.GotdSuggestionInternal, int64, error)
 	GetGotdSuggestion(dbs PGDBSession, sugId int64, fpfss types.IFpfss) (*types.GotdSuggestionInternal, error)
+	GetPendingGotdSuggestions(dbs PGDBSession, fpfss types.IFpfss) ([]*types.GotdSuggestionInternal, error)
 	DeleteGotdSuggestion(dbs PGDBSession, uid string, sugId int64) error
 	SaveGotdSuggestion(dbs PGDBSession, uid string, suggestion *types.GotdSuggestionInternal) error
+	CountUserGotdSuggestions(dbs PGDBSession, uid string) (int64, error)
+	GotdSuggestionExists(dbs PGDBSession, gameID string, suggestedDate *time.Time) (bool, error)
 	GetGotdCurrent(dbs PGDBSession, query *types.GetGotdCurrentQuery) ([]*types.GotdGame, error)
+	GetGotdByDate(dbs PGDBSession, date string) (*types.GotdGame, error)
+	GetGotdHistoryForGame(dbs PGDBSession, gameID string) ([]*types.GotdGame, error)
 	AssignGotd(dbs PGDBSession, uid string, sugId int64, date string, fpfss types.IFpfss) error
 	UnassignGotd(dbs PGDBSession, uid string, date string) error
+	SaveGotd(dbs PGDBSession, uid string, game *types.GotdGame) error
+	GetGotdScheduleUpdatedAt(dbs PGDBSession) (time.Time, error)
 }
 
 type PGDBSession interface {
@@ -3,16 +3,15 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/FlashpointProject/CommunityWebsite/config"
-	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
-	"github.com/jackc/pgx/pgtype"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
@@ -89,8 +88,9 @@ func (dbs *PostgresSession) Ctx() context.Context {
 	return dbs.context
 }
 
-func (dal *postgresDAL) StoreSession(dbs PGDBSession, secret string, uid string, durationSeconds int64, ipAddr string) error {
-	_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO session (secret, uid, expires_at, ip_addr) VALUES ($1, $2, NOW() + ($3 * INTERVAL '1 second'), $4)", secret, uid, durationSeconds, ipAddr)
+// StoreSession stores a new session under the keyed hash of its secret, the secret itself is never stored
+func (dal *postgresDAL) StoreSession(dbs PGDBSession, secretHash string, uid string, durationSeconds int64, ipAddr string, userAgent string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO session (secret_hash, uid, expires_at, ip_addr, user_agent) VALUES ($1, $2, NOW() + ($3 * INTERVAL '1 second'), $4, $5)", secretHash, uid, durationSeconds, ipAddr, userAgent)
 	if err != nil {
 		return err
 	}
@@ -133,13 +133,48 @@ func (dal *postgresDAL) SaveRoles(dbs PGDBSession, roles []*types.DiscordRole) e
 }
 
 func (dal *postgresDAL) SaveUser(dbs PGDBSession, uid string, name string, avatarURL string, roles []string) error {
-	_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO fpcomm_user (id, name, avatar, roles, updated_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP) ON CONFLICT(id) DO UPDATE SET name = $2, avatar = $3, roles = $4, updated_at = CURRENT_TIMESTAMP", uid, name, avatarURL, roles)
+	// roles_updated_at only moves when the roles actually change, sessions issued before it get rotated
+	_, err := dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO fpcomm_user (id, name, avatar, roles, updated_at) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT(id) DO UPDATE SET name = $2, avatar = $3, roles = $4, updated_at = CURRENT_TIMESTAMP,
+		roles_updated_at = CASE WHEN fpcomm_user.roles IS DISTINCT FROM $4 THEN CURRENT_TIMESTAMP ELSE fpcomm_user.roles_updated_at END`, uid, name, avatarURL, roles)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// UpdateUserRoles replaces the user's roles, reporting whether they changed. Roles are compared as a set so
+// FPFSS returning them in another order doesn't count as a change and rotate the user's sessions.
+func (dal *postgresDAL) UpdateUserRoles(dbs PGDBSession, uid string, roles []string) (bool, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), `UPDATE fpcomm_user SET roles = $2, roles_updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT (COALESCE(roles, '{}') @> $2::text[] AND $2::text[] @> COALESCE(roles, '{}'))`, uid, roles)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetActiveUserIDs returns the users with an unexpired session or API token
+func (dal *postgresDAL) GetActiveUserIDs(dbs PGDBSession) ([]string, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT uid FROM session WHERE expires_at > NOW()
+		UNION SELECT uid FROM api_token WHERE expires_at IS NULL OR expires_at > NOW()`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 func (dal *postgresDAL) GetUser(dbs PGDBSession, uid string) (*types.UserProfile, error) {
 	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT name, avatar, roles, updated_at FROM fpcomm_user WHERE id=$1", uid)
 
@@ -161,15 +196,23 @@ func (dal *postgresDAL) GetUser(dbs PGDBSession, uid string) (*types.UserProfile
 	}, nil
 }
 
-// GetSessionAuthInfo returns user ID + scope and/or expiration state
-func (d *postgresDAL) GetSessionAuthInfo(dbs PGDBSession, secret string) (*types.SessionInfo, bool, error) {
-	row := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT id, uid, expires_at, ip_addr FROM session WHERE secret=$1`, secret)
+// GetSessionAuthInfo returns user ID + scope and/or expiration state. Looking up by keyed hash means the
+// index comparison can't leak anything about a valid secret, so no separate constant-time check is needed.
+func (d *postgresDAL) GetSessionAuthInfo(dbs PGDBSession, secretHash string) (*types.SessionInfo, bool, error) {
+	row := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT s.id, s.uid, s.created_at, s.expires_at, s.ip_addr, s.user_agent,
+		COALESCE(u.roles_updated_at > s.rotated_at, false), u.roles
+		FROM session s LEFT JOIN fpcomm_user u ON u.id = s.uid
+		WHERE s.secret_hash=$1`, secretHash)
 
 	var id int64
 	var uid string
+	var createdAt time.Time
 	var expiration time.Time
 	var ipAddr string
-	err := row.Scan(&id, &uid, &expiration, &ipAddr)
+	var userAgent string
+	var rotationRequired bool
+	var roles []string
+	err := row.Scan(&id, &uid, &createdAt, &expiration, &ipAddr, &userAgent, &rotationRequired, &roles)
 	if err != nil {
 		return nil, false, err
 	}
@@ -179,19 +222,210 @@ func (d *postgresDAL) GetSessionAuthInfo(dbs PGDBSession, secret string) (*types
 	}
 
 	return &types.SessionInfo{
-		ID:        id,
-		UID:       uid,
-		IpAddr:    ipAddr,
-		ExpiresAt: expiration,
+		ID:               id,
+		UID:              uid,
+		CreatedAt:        createdAt,
+		IpAddr:           ipAddr,
+		UserAgent:        userAgent,
+		ExpiresAt:        expiration,
+		Roles:            roles,
+		RotationRequired: rotationRequired,
 	}, true, nil
 }
 
-func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSearchQuery) ([]*types.Playlist, int64, error) {
-	total := 0
+// RotateSession replaces the session's secret hash, reporting false if the old hash no longer matches
+// because a concurrent request rotated it first
+func (d *postgresDAL) RotateSession(dbs PGDBSession, id int64, oldSecretHash string, newSecretHash string) (bool, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "UPDATE session SET secret_hash = $1, rotated_at = CURRENT_TIMESTAMP WHERE id = $2 AND secret_hash = $3", newSecretHash, id, oldSecretHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
 
-	playlists := make([]*types.Playlist, 0)
+// GetUserSessions returns the user's unexpired sessions, newest first
+func (d *postgresDAL) GetUserSessions(dbs PGDBSession, uid string) ([]*types.SessionInfo, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT id, created_at, expires_at, ip_addr, user_agent FROM session
+		WHERE uid = $1 AND expires_at > NOW() ORDER BY created_at DESC`, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*types.SessionInfo, 0)
+	for rows.Next() {
+		session := &types.SessionInfo{UID: uid}
+		err := rows.Scan(&session.ID, &session.CreatedAt, &session.ExpiresAt, &session.IpAddr, &session.UserAgent)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteSession deletes one of the user's sessions, reporting false if they had no session with that ID
+func (d *postgresDAL) DeleteSession(dbs PGDBSession, uid string, id int64) (bool, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM session WHERE id = $1 AND uid = $2", id, uid)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteSessionBySecret deletes the session the secret hash belongs to, if any
+func (d *postgresDAL) DeleteSessionBySecret(dbs PGDBSession, secretHash string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM session WHERE secret_hash = $1", secretHash)
+	return err
+}
+
+// DeleteUserSessions deletes every session of the user and returns how many there were
+func (d *postgresDAL) DeleteUserSessions(dbs PGDBSession, uid string) (int64, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM session WHERE uid = $1", uid)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeExpiredSessions deletes every expired session and returns how many there were
+func (d *postgresDAL) PurgeExpiredSessions(dbs PGDBSession) (int64, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM session WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// SaveOAuthState stores a pending login expiring ttl from now and fills in its ExpiresAt. The expiry is set and
+// checked by the database alone, so the app server's time zone can't skew it.
+func (d *postgresDAL) SaveOAuthState(dbs PGDBSession, state *types.OAuthState, ttl time.Duration) error {
+	return dbs.Tx().QueryRow(dbs.Ctx(), `INSERT INTO oauth_state (nonce, redirect_uri, code_verifier, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4::float8 * INTERVAL '1 second') RETURNING expires_at`,
+		state.Nonce, state.RedirectURI, state.CodeVerifier, ttl.Seconds()).Scan(&state.ExpiresAt)
+}
+
+// ConsumeOAuthState deletes and returns the state with the given nonce, returning nil if there is none or it has expired.
+// Deleting it makes the state single use even with several instances racing on the same callback.
+func (d *postgresDAL) ConsumeOAuthState(dbs PGDBSession, nonce string) (*types.OAuthState, error) {
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "DELETE FROM oauth_state WHERE nonce = $1 AND expires_at > NOW() RETURNING redirect_uri, code_verifier, expires_at", nonce)
+
+	state := &types.OAuthState{Nonce: nonce}
+	err := row.Scan(&state.RedirectURI, &state.CodeVerifier, &state.ExpiresAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// PurgeExpiredOAuthStates deletes logins that were never completed and returns how many there were
+func (d *postgresDAL) PurgeExpiredOAuthStates(dbs PGDBSession) (int64, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM oauth_state WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetApiTokenAuthInfo returns the owner and scopes of the API token with the given hash, reporting false if it has expired
+func (d *postgresDAL) GetApiTokenAuthInfo(dbs PGDBSession, tokenHash string) (*types.SessionInfo, bool, error) {
+	row := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT id, uid, scopes, created_at, expires_at FROM api_token WHERE token_hash=$1`, tokenHash)
+
+	info := &types.SessionInfo{}
+	var expiresAt *time.Time
+	err := row.Scan(&info.TokenID, &info.UID, &info.Scopes, &info.CreatedAt, &expiresAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if expiresAt != nil {
+		if expiresAt.Unix() <= time.Now().Unix() {
+			return nil, false, nil
+		}
+		info.ExpiresAt = *expiresAt
+	}
+
+	return info, true, nil
+}
+
+// TouchApiToken records that the token was used, at most once a minute to keep writes down
+func (d *postgresDAL) TouchApiToken(dbs PGDBSession, id int64) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), `UPDATE api_token SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')`, id)
+	return err
+}
+
+func (d *postgresDAL) GetApiTokens(dbs PGDBSession, uid string) ([]*types.ApiToken, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT id, name, scopes, created_at, expires_at, last_used_at FROM api_token
+		WHERE uid = $1 ORDER BY created_at DESC`, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*types.ApiToken, 0)
+	for rows.Next() {
+		token := &types.ApiToken{}
+		err := rows.Scan(&token.ID, &token.Name, &token.Scopes, &token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (d *postgresDAL) CountApiTokens(dbs PGDBSession, uid string) (int64, error) {
+	var count int64
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT COUNT(*) FROM api_token WHERE uid = $1", uid).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SaveApiToken stores a new token under the keyed hash of its secret and fills in its ID and creation time
+func (d *postgresDAL) SaveApiToken(dbs PGDBSession, uid string, tokenHash string, token *types.ApiToken) error {
+	return dbs.Tx().QueryRow(dbs.Ctx(), `INSERT INTO api_token (uid, name, token_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`, uid, token.Name, tokenHash, token.Scopes, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+}
+
+// DeleteApiToken deletes one of the user's tokens, reporting false if they had no token with that ID
+func (d *postgresDAL) DeleteApiToken(dbs PGDBSession, uid string, id int64) (bool, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM api_token WHERE id = $1 AND uid = $2", id, uid)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteUserApiTokens deletes every token of the user and returns how many there were
+func (d *postgresDAL) DeleteUserApiTokens(dbs PGDBSession, uid string) (int64, error) {
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM api_token WHERE uid = $1", uid)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// playlistForkCountColumn counts the public forks of each selected playlist
+const playlistForkCountColumn = "(SELECT COUNT(*) FROM playlist f WHERE f.forked_from = playlist.id AND f.public = true)"
 
-	builder := NewSqlBuilder("SELECT id, name, total_games, description, author_id, icon, library, public, extreme, filter_groups, created_at, updated_at FROM playlist")
+// playlistTrendingColumn scores each selected playlist by its likes, each like's weight halving every three days
+const playlistTrendingColumn = "(SELECT COALESCE(SUM(POWER(0.5, EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - l.created_at)) / 259200)), 0)::float8 FROM playlist_like l WHERE l.playlist_id = playlist.id)"
+
+// newPlaylistSearchBuilder starts a query on the playlists matching the search
+func newPlaylistSearchBuilder(base string, query *types.PlaylistSearchQuery) *SqlBuilder {
+	builder := NewSqlBuilder(base)
+	if query.Query != "" {
+		builder.Where("search_vector @@ websearch_to_tsquery('english', $1)", query.Query)
+	}
 	if query.UserID != "" {
 		builder.Where("author_id=$1", query.UserID)
 	}
@@ -201,15 +435,144 @@ func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSear
 	if query.Title != "" {
 		builder.Where("name ILIKE $1", "%"+query.Title+"%")
 	}
-	if !query.Extreme {
-		builder.Where("extreme=false")
+	if query.GameID != "" {
+		builder.Where("id IN (SELECT playlist_id FROM playlist_game WHERE game_id=$1)", query.GameID)
+	}
+	if query.Platform != "" {
+		builder.Where("id IN (SELECT pg.playlist_id FROM playlist_game pg JOIN game_cache g ON g.id = pg.game_id WHERE g.platform_name=$1)", query.Platform)
+	}
+	if query.Tag != "" {
+		builder.Where(`id IN (SELECT pg.playlist_id FROM playlist_game pg
+			JOIN game_tag_cache gt ON gt.game_id::text = pg.game_id
+			JOIN tag_cache t ON t.id = gt.tag_id
+			WHERE t.name=$1)`, query.Tag)
+	}
+	if query.FilterGroup != "" {
+		builder.Where("$1 = ANY(filter_groups)", query.FilterGroup)
+	}
+	if query.ViewerID != "" {
+		builder.Where("(public=true OR author_id=$1 OR id IN (SELECT playlist_id FROM playlist_collaborator WHERE uid=$1 AND accepted_at IS NOT NULL))", query.ViewerID)
+	} else {
+		builder.Where("public=true")
+	}
+	if query.BookmarkedBy != "" {
+		builder.Where("id IN (SELECT playlist_id FROM playlist_bookmark WHERE uid=$1)", query.BookmarkedBy)
+	}
+	if query.Filter != nil {
+		if query.Filter.HideExtreme {
+			builder.Where("extreme=false")
+		}
+		if len(query.Filter.HiddenFilterGroups) > 0 {
+			builder.Where("NOT (filter_groups && $1::text[])", query.Filter.HiddenFilterGroups)
+		}
+	}
+	return builder
+}
+
+// GetPlaylistSearchFacets counts the playlists matching the search by library, by platform of the games in them,
+// and by filter group
+func (d *postgresDAL) GetPlaylistSearchFacets(dbs PGDBSession, query *types.PlaylistSearchQuery) (*types.PlaylistSearchFacets, error) {
+	builder := newPlaylistSearchBuilder("SELECT id, library, filter_groups FROM playlist", query)
+	matched := "WITH matched AS (" + builder.Count(0) + ") "
+	args := builder.ArgumentsCount()
+
+	facets := &types.PlaylistSearchFacets{}
+	var err error
+	facets.Library, err = d.readFacetCounts(dbs, matched+"SELECT library, COUNT(*) FROM matched GROUP BY library ORDER BY 2 DESC, 1", args)
+	if err != nil {
+		return nil, err
+	}
+	facets.Platform, err = d.readFacetCounts(dbs, matched+`SELECT g.platform_name::text, COUNT(DISTINCT m.id) FROM matched m
+		JOIN playlist_game pg ON pg.playlist_id = m.id
+		JOIN game_cache g ON g.id = pg.game_id
+		WHERE COALESCE(g.platform_name, '') <> ''
+		GROUP BY 1 ORDER BY 2 DESC, 1`, args)
+	if err != nil {
+		return nil, err
+	}
+	facets.FilterGroup, err = d.readFacetCounts(dbs, matched+"SELECT fg, COUNT(*) FROM matched m CROSS JOIN LATERAL unnest(m.filter_groups) AS fg GROUP BY fg ORDER BY 2 DESC, 1", args)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+func (d *postgresDAL) readFacetCounts(dbs PGDBSession, sqlQuery string, args []interface{}) ([]*types.FacetCount, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]*types.FacetCount, 0)
+	for rows.Next() {
+		count := &types.FacetCount{}
+		err := rows.Scan(&count.Value, &count.Count)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return counts, nil
+}
+
+// playlistSearchVectorSQL rebuilds search_vector from the playlist's name and description, then the titles,
+// developers and tags of its games, for the playlists matched by the WHERE condition appended to it
+const playlistSearchVectorSQL = `UPDATE playlist p SET search_vector =
+	setweight(to_tsvector('english', p.name::text), 'A') ||
+	setweight(to_tsvector('english', p.description::text), 'B') ||
+	setweight(to_tsvector('english', COALESCE((
+		SELECT string_agg(concat_ws(' ', g.title::text, g.developer::text), ' ')
+		FROM playlist_game pg JOIN game_cache g ON g.id = pg.game_id
+		WHERE pg.playlist_id = p.id), '')), 'C') ||
+	setweight(to_tsvector('english', COALESCE((
+		SELECT string_agg(DISTINCT t.name::text, ' ')
+		FROM playlist_game pg JOIN game_tag_cache gt ON gt.game_id::text = pg.game_id JOIN tag_cache t ON t.id = gt.tag_id
+		WHERE pg.playlist_id = p.id), '')), 'D')
+	WHERE `
+
+// RefreshGamePlaylistsSearch rebuilds the search text of every playlist containing any of the games
+func (d *postgresDAL) RefreshGamePlaylistsSearch(dbs PGDBSession, gameIDs []string) (int64, error) {
+	res, err := dbs.Tx().Exec(dbs.Ctx(), playlistSearchVectorSQL+"p.id IN (SELECT playlist_id FROM playlist_game WHERE game_id = ANY($1))", gameIDs)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSearchQuery) ([]*types.Playlist, int64, error) {
+	total := 0
+
+	playlists := make([]*types.Playlist, 0)
+
+	if query.Query != "" && query.OrderBy == "" {
+		query.OrderBy = "relevance"
+		query.OrderDirection = "desc"
 	}
+	builder := newPlaylistSearchBuilder("", query)
 	builder.Limit(query.PageSize)
 	builder.Offset((query.Page - 1) * query.PageSize)
-	builder.OrderBy(query.OrderBy, query.OrderDirection, []string{"name", "created_at", "updated_at", "total_games"})
+	builder.OrderBy(query.OrderBy, query.OrderDirection, []string{"name", "created_at", "updated_at", "total_games", "likes", "trending", "relevance"})
+	args := builder.Arguments()
+
+	// The rank gets its own copy of the query after every other argument
+	relevance := "0::real"
+	if query.Query != "" {
+		args = append(args, query.Query)
+		relevance = fmt.Sprintf("ts_rank(search_vector, websearch_to_tsquery('english', $%d))", len(args))
+	}
+	// Scoring every like is only worth it when sorting by it
+	trending := "0::float8"
+	if query.OrderBy == "trending" {
+		trending = playlistTrendingColumn
+	}
+	builder.SetBase("SELECT id, name, total_games, description, author_id, icon, library, public, extreme, filter_groups, forked_from, " + playlistForkCountColumn + ", like_count AS likes, " + trending + " AS trending, " + relevance + " AS relevance, created_at, updated_at FROM playlist")
 
 	sqlQuery := builder.Build(0)
-	args := builder.Arguments()
 	rows, err := dbs.Tx().Query(dbs.Ctx(), sqlQuery, args...)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -230,9 +593,14 @@ func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSear
 		var public bool
 		var extreme bool
 		var filterGroups []string
+		var forkedFrom *int64
+		var forkCount int64
+		var likes int64
+		var trending float64
+		var relevance float32
 		var createdAt time.Time
 		var updatedAt time.Time
-		err := rows.Scan(&id, &name, &totalGames, &description, &authorID, &icon, &library, &public, &extreme, &filterGroups, &createdAt, &updatedAt)
+		err := rows.Scan(&id, &name, &totalGames, &description, &authorID, &icon, &library, &public, &extreme, &filterGroups, &forkedFrom, &forkCount, &likes, &trending, &relevance, &createdAt, &updatedAt)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -256,6 +624,9 @@ func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSear
 			Public:       public,
 			Extreme:      extreme,
 			FilterGroups: filterGroups,
+			ForkedFrom:   forkedFrom,
+			ForkCount:    forkCount,
+			Likes:        likes,
 			CreatedAt:    createdAt,
 			UpdatedAt:    updatedAt,
 		})
@@ -280,7 +651,7 @@ func (d *postgresDAL) SearchPlaylists(dbs PGDBSession, query *types.PlaylistSear
 }
 
 func (d *postgresDAL) GetPlaylist(dbs PGDBSession, id int64) (*types.Playlist, error) {
-	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, name, total_games, description, author_id, icon, library, public, extreme, filter_groups, created_at, updated_at FROM playlist WHERE id=$1", id)
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, name, total_games, description, author_id, icon, library, public, extreme, filter_groups, forked_from, "+playlistForkCountColumn+", like_count, created_at, updated_at FROM playlist WHERE id=$1", id)
 
 	var name string
 	var totalGames int
@@ -291,9 +662,12 @@ func (d *postgresDAL) GetPlaylist(dbs PGDBSession, id int64) (*types.Playlist, e
 	var public bool
 	var extreme bool
 	var filterGroups []string
+	var forkedFrom *int64
+	var forkCount int64
+	var likes int64
 	var createdAt time.Time
 	var updatedAt time.Time
-	err := row.Scan(&id, &name, &totalGames, &description, &authorID, &icon, &library, &public, &extreme, &filterGroups, &createdAt, &updatedAt)
+	err := row.Scan(&id, &name, &totalGames, &description, &authorID, &icon, &library, &public, &extreme, &filterGroups, &forkedFrom, &forkCount, &likes, &createdAt, &updatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -331,6 +705,9 @@ func (d *postgresDAL) GetPlaylist(dbs PGDBSession, id int64) (*types.Playlist, e
 		Public:       public,
 		Extreme:      extreme,
 		FilterGroups: filterGroups,
+		ForkedFrom:   forkedFrom,
+		ForkCount:    forkCount,
+		Likes:        likes,
 		CreatedAt:    createdAt,
 		UpdatedAt:    updatedAt,
 		Games:        games,
@@ -342,7 +719,7 @@ func (d *postgresDAL) GetPlaylist(dbs PGDBSession, id int64) (*types.Playlist, e
 func (d *postgresDAL) GetPlaylistGames(dbs PGDBSession, id int64) ([]types.LauncherPlaylistGame, error) {
 	games := make([]types.LauncherPlaylistGame, 0)
 
-	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT game_id, notes FROM playlist_game WHERE playlist_id=$1", id)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT game_id, notes FROM playlist_game WHERE playlist_id=$1 ORDER BY position ASC", id)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return games, nil
@@ -367,6 +744,8 @@ func (d *postgresDAL) GetPlaylistGames(dbs PGDBSession, id int64) ([]types.Launc
 	return games, nil
 }
 
+// GetGames returns games from the cache, fetching any that have never been cached from FPFSS.
+// Stale entries are served as-is, the game cache refresher keeps them up to date.
 func (d *postgresDAL) GetGames(dbs PGDBSession, ids []string, fpfss types.IFpfss) ([]*types.CachedGame, error) {
 	games := make([]*types.CachedGame, len(ids))
 	for i, id := range ids {
@@ -375,226 +754,53 @@ func (d *postgresDAL) GetGames(dbs PGDBSession, ids []string, fpfss types.IFpfss
 			Missing: true,
 		}
 	}
-	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT id, title, series, developer, publisher, release_date, play_mode,
-	 	language, original_description, platform_name, extreme, filter_groups, updated_at FROM game_cache WHERE id=ANY($1)`, ids)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT "+GameCacheColumns+" FROM game_cache WHERE id=ANY($1)", ids)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return games, nil
-		}
 		utils.LogCtx(dbs.Ctx()).Error(err)
 		return nil, err
 	}
-	defer rows.Close()
 
+	cached := make(map[string]*types.CachedGame)
 	for rows.Next() {
-		var id string
-		var title string
-		var series string
-		var developer string
-		var publisher string
-		var releaseDate string
-		var playMode []string
-		var language []string
-		var originalDescription string
-		var platformName string
-		var extreme bool
-		var filterGroups []string
-		var updatedAt time.Time
-		err := rows.Scan(&id, &title, &series, &developer, &publisher, &releaseDate, &playMode, &language, &originalDescription, &platformName, &extreme, &filterGroups, &updatedAt)
+		game, err := ReadGame(rows)
 		if err != nil {
+			rows.Close()
 			utils.LogCtx(dbs.Ctx()).Error(err)
 			return nil, err
 		}
-		for _, game := range games {
-			if game.ID == id {
-				game.Title = title
-				game.Series = series
-				game.Developer = developer
-				game.Publisher = publisher
-				game.ReleaseDate = releaseDate
-				game.PlayMode = playMode
-				game.Language = language
-				game.OriginalDescription = originalDescription
-				game.Platform = platformName
-				game.UpdatedAt = updatedAt
-				game.Extreme = extreme
-				game.FilterGroups = filterGroups
-				game.Missing = false
-				break
-			}
-		}
+		cached[strings.ToLower(game.ID)] = game
 	}
-
-	// Check for outdated games
-	yesterday := time.Now().Add(-time.Hour * 24)
-	outdatedIds := make([]string, 0)
-	for _, game := range games {
-		if !game.Missing && game.UpdatedAt.Before(yesterday) {
-			outdatedIds = append(outdatedIds, game.ID)
-		}
-	}
-	if len(outdatedIds) != 0 {
-		fpfssGames, err := fpfss.GetGames(outdatedIds)
-		if err != nil {
-			utils.LogCtx(dbs.Ctx()).Error(err)
-			return nil, err
-		}
-		for _, fpfssGame := range fpfssGames {
-			for i, game := range games {
-				if game.ID == fpfssGame.ID {
-					playModesArr := strings.Split(fpfssGame.PlayMode, ";")
-					for i, pm := range playModesArr {
-						playModesArr[i] = strings.TrimSpace(pm)
-					}
-					playModes := &pgtype.TextArray{}
-					err := playModes.Set(playModesArr)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
-					languagesArr := strings.Split(fpfssGame.Language, ";")
-					for i, lang := range languagesArr {
-						languagesArr[i] = strings.TrimSpace(lang)
-					}
-					languages := &pgtype.TextArray{}
-					err = languages.Set(languagesArr)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
-
-					// Build tag cache
-					extreme := false
-					var filterGroups = make([]string, 0)
-					for _, tag := range fpfssGame.Tags {
-						for _, filterGroup := range constants.GetFilterGroups() {
-							if utils.StringInSlice(tag.Name, filterGroup.Tags) {
-								filterGroups = append(filterGroups, filterGroup.Name)
-								if filterGroup.Extreme {
-									extreme = true
-								}
-								break
-							}
-						}
-						_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO tag_cache (id, name, description, category) VALUES ($1, $2, $3, $4) ON CONFLICT(id) DO UPDATE SET name = $2, description = $3, category = $4, updated_at = CURRENT_TIMESTAMP", tag.ID, tag.Name, tag.Description, tag.Category)
-						if err != nil {
-							utils.LogCtx(dbs.Ctx()).Error(err)
-							return nil, err
-						}
-					}
-					filterGroups = utils.RemoveSliceDuplicates(filterGroups)
-					sort.Strings(filterGroups)
-
-					games[i] = &types.CachedGame{
-						ID:                  fpfssGame.ID,
-						Title:               fpfssGame.Title,
-						Series:              fpfssGame.Series,
-						Developer:           fpfssGame.Developer,
-						Publisher:           fpfssGame.Publisher,
-						ReleaseDate:         fpfssGame.ReleaseDate,
-						PlayMode:            playModesArr,
-						Language:            languagesArr,
-						OriginalDescription: fpfssGame.OriginalDescription,
-						Platform:            fpfssGame.Platform,
-						Extreme:             extreme,
-						FilterGroups:        filterGroups,
-						UpdatedAt:           time.Now(),
-					}
-					// Save to cache
-					_, err = dbs.Tx().Exec(dbs.Ctx(), "UPDATE game_cache SET title=$2, series=$3, developer=$4, publisher=$5, release_date=$6, play_mode=$7, language=$8, original_description=$9, platform_name=$10, updated_at=CURRENT_TIMESTAMP WHERE id=$1",
-						games[i].ID, games[i].Title, games[i].Series, games[i].Developer, games[i].Publisher, games[i].ReleaseDate, playModes, languages, games[i].OriginalDescription, games[i].Platform)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
-
-					// Save tags as well
-				}
-			}
-		}
+	rows.Close()
+	if rows.Err() != nil {
+		utils.LogCtx(dbs.Ctx()).Error(rows.Err())
+		return nil, rows.Err()
 	}
 
 	idsMissing := make([]string, 0)
-	for _, game := range games {
-		if game.Missing {
+	for i, game := range games {
+		if cachedGame, ok := cached[strings.ToLower(game.ID)]; ok {
+			games[i] = cachedGame
+		} else {
 			idsMissing = append(idsMissing, game.ID)
 		}
 	}
+	idsMissing = utils.RemoveSliceDuplicates(idsMissing)
+
 	if len(idsMissing) != 0 {
-		fpfssGames, err := fpfss.GetGames(idsMissing)
+		fpfssGames, err := fpfss.GetGames(dbs.Ctx(), idsMissing)
 		if err != nil {
 			utils.LogCtx(dbs.Ctx()).Error(err)
 			return nil, err
 		}
 		for _, fpfssGame := range fpfssGames {
+			cachedGame, err := d.cacheFpfssGame(dbs, fpfssGame)
+			if err != nil {
+				utils.LogCtx(dbs.Ctx()).Error(err)
+				return nil, err
+			}
 			for i, game := range games {
-				if game.ID == fpfssGame.ID {
-					// Safely convert to postgres arrays
-					playModesArr := strings.Split(fpfssGame.PlayMode, ";")
-					for i, pm := range playModesArr {
-						playModesArr[i] = strings.TrimSpace(pm)
-					}
-					playModes := &pgtype.TextArray{}
-					err := playModes.Set(playModesArr)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
-					languagesArr := strings.Split(fpfssGame.Language, ";")
-					for i, lang := range languagesArr {
-						languagesArr[i] = strings.TrimSpace(lang)
-					}
-					languages := &pgtype.TextArray{}
-					err = languages.Set(languagesArr)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
-
-					// Build tag cache
-					extreme := false
-					var filterGroups = make([]string, 0)
-					for _, tag := range fpfssGame.Tags {
-						for _, filterGroup := range constants.GetFilterGroups() {
-							if utils.StringInSlice(tag.Name, filterGroup.Tags) {
-								filterGroups = append(filterGroups, filterGroup.Name)
-								if filterGroup.Extreme {
-									extreme = true
-								}
-								break
-							}
-						}
-						_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO tag_cache (id, name, description, category) VALUES ($1, $2, $3, $4) ON CONFLICT(id) DO UPDATE SET name = $2, description = $3, category = $4, updated_at = CURRENT_TIMESTAMP", tag.ID, tag.Name, tag.Description, tag.Category)
-						if err != nil {
-							utils.LogCtx(dbs.Ctx()).Error(err)
-							return nil, err
-						}
-					}
-					filterGroups = utils.RemoveSliceDuplicates(filterGroups)
-					sort.Strings(filterGroups)
-
-					games[i] = &types.CachedGame{
-						ID:                  fpfssGame.ID,
-						Title:               fpfssGame.Title,
-						Series:              fpfssGame.Series,
-						Developer:           fpfssGame.Developer,
-						Publisher:           fpfssGame.Publisher,
-						ReleaseDate:         fpfssGame.ReleaseDate,
-						PlayMode:            playModesArr,
-						Language:            languagesArr,
-						OriginalDescription: fpfssGame.OriginalDescription,
-						Platform:            fpfssGame.Platform,
-						Extreme:             extreme,
-						FilterGroups:        filterGroups,
-						UpdatedAt:           time.Now(),
-					}
-					// Save to cache
-					_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO game_cache (id, title, series, developer, publisher, release_date, play_mode, language, original_description, platform_name, extreme, filter_groups, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP)",
-						games[i].ID, games[i].Title, games[i].Series, games[i].Developer, games[i].Publisher, games[i].ReleaseDate, playModes, languages, games[i].OriginalDescription, games[i].Platform, games[i].Extreme, games[i].FilterGroups)
-					if err != nil {
-						utils.LogCtx(dbs.Ctx()).Error(err)
-						return nil, err
-					}
+				if strings.EqualFold(game.ID, fpfssGame.ID) {
+					games[i] = cachedGame
 				}
 			}
 		}
@@ -603,167 +809,135 @@ func (d *postgresDAL) GetGames(dbs PGDBSession, ids []string, fpfss types.IFpfss
 	return games, nil
 }
 
+// GetGame returns a game from the cache, fetching it from FPFSS if it has never been cached
 func (d *postgresDAL) GetGame(dbs PGDBSession, gameId string, fpfss types.IFpfss) (*types.CachedGame, error) {
-	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, title, series, developer, publisher, release_date, play_mode, language, original_description, platform_name, updated_at FROM game_cache WHERE id=$1", gameId)
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT "+GameCacheColumns+" FROM game_cache WHERE id=$1", gameId)
 	game, err := ReadGame(row)
 	if err != nil {
 		return nil, err
 	}
+	if game != nil {
+		return game, nil
+	}
 
-	if game == nil {
-		// No game, fetch from fpfss
-		fpfssGame, err := fpfss.GetGame(gameId)
+	// No game, fetch from fpfss
+	fpfssGame, err := fpfss.GetGame(dbs.Ctx(), gameId)
+	if err != nil {
+		return nil, err
+	}
+	if fpfssGame == nil {
+		return nil, nil
+	}
+	return d.cacheFpfssGame(dbs, fpfssGame)
+}
+
+// CacheGames saves fresh FPFSS data for each game, marking them as no longer removed
+func (d *postgresDAL) CacheGames(dbs PGDBSession, fpfssGames []*types.FpfssGame) error {
+	for _, fpfssGame := range fpfssGames {
+		_, err := d.cacheFpfssGame(dbs, fpfssGame)
 		if err != nil {
-			return nil, err
-		}
-		if fpfssGame == nil {
-			return nil, nil
-		}
-		playModesArr := strings.Split(fpfssGame.PlayMode, ";")
-		for i, pm := range playModesArr {
-			playModesArr[i] = strings.TrimSpace(pm)
+			return err
 		}
-		playModes := &pgtype.TextArray{}
-		err = playModes.Set(playModesArr)
+	}
+	return nil
+}
+
+// GetStaleGameIDs returns up to limit cached game IDs last refreshed before the given time, oldest first
+func (d *postgresDAL) GetStaleGameIDs(dbs PGDBSession, before time.Time, limit int64) ([]string, error) {
+	ids := make([]string, 0)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT id FROM game_cache WHERE updated_at < $1 ORDER BY updated_at ASC LIMIT $2", before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		err := rows.Scan(&id)
 		if err != nil {
 			return nil, err
 		}
-		languagesArr := strings.Split(fpfssGame.Language, ";")
-		for i, lang := range languagesArr {
-			languagesArr[i] = strings.TrimSpace(lang)
-		}
-		languages := &pgtype.TextArray{}
-		err = languages.Set(languagesArr)
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MarkGamesRemoved flags cached games that FPFSS no longer returns, keeping them for existing references
+func (d *postgresDAL) MarkGamesRemoved(dbs PGDBSession, ids []string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "UPDATE game_cache SET removed = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id=ANY($1)", ids)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// cacheFpfssGame upserts a game and its tags into the cache
+func (d *postgresDAL) cacheFpfssGame(dbs PGDBSession, fpfssGame *types.FpfssGame) (*types.CachedGame, error) {
+	playModes := strings.Split(fpfssGame.PlayMode, ";")
+	for i, pm := range playModes {
+		playModes[i] = strings.TrimSpace(pm)
+	}
+	languages := strings.Split(fpfssGame.Language, ";")
+	for i, lang := range languages {
+		languages[i] = strings.TrimSpace(lang)
+	}
+
+	// Build tag cache
+	tagNames := make([]string, 0)
+	for _, tag := range fpfssGame.Tags {
+		tagNames = append(tagNames, tag.Name)
+		_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO tag_cache (id, name, description, category) VALUES ($1, $2, $3, $4) ON CONFLICT(id) DO UPDATE SET name = $2, description = $3, category = $4, updated_at = CURRENT_TIMESTAMP", tag.ID, tag.Name, tag.Description, tag.Category)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// Build tag cache
-		extreme := false
-		var filterGroups = make([]string, 0)
-		for _, tag := range fpfssGame.Tags {
-			for _, filterGroup := range constants.GetFilterGroups() {
-				if utils.StringInSlice(tag.Name, filterGroup.Tags) {
-					if filterGroup.Extreme {
-						extreme = true
-					}
-					filterGroups = append(filterGroups, filterGroup.Name)
-					break
-				}
-			}
-			_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO tag_cache (id, name, description, category) VALUES ($1, $2, $3, $4) ON CONFLICT(id) DO UPDATE SET name = $2, description = $3, category = $4, updated_at = CURRENT_TIMESTAMP", tag.ID, tag.Name, tag.Description, tag.Category)
-			if err != nil {
-				utils.LogCtx(dbs.Ctx()).Error(err)
-				return nil, err
-			}
-		}
-		filterGroups = utils.RemoveSliceDuplicates(filterGroups)
-		sort.Strings(filterGroups)
-
-		game := &types.CachedGame{
-			ID:                  fpfssGame.ID,
-			Title:               fpfssGame.Title,
-			Series:              fpfssGame.Series,
-			Developer:           fpfssGame.Developer,
-			Publisher:           fpfssGame.Publisher,
-			ReleaseDate:         fpfssGame.ReleaseDate,
-			PlayMode:            playModesArr,
-			Language:            languagesArr,
-			OriginalDescription: fpfssGame.OriginalDescription,
-			Platform:            fpfssGame.Platform,
-			Extreme:             extreme,
-			FilterGroups:        filterGroups,
-			UpdatedAt:           time.Now(),
-		}
-		_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO game_cache (id, title, series, developer, publisher, release_date, play_mode, language, original_description, platform_name, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, CURRENT_TIMESTAMP)",
-			game.ID, game.Title, game.Series, game.Developer, game.Publisher, game.ReleaseDate, playModes, languages, game.OriginalDescription, game.Platform)
+	// Every group containing one of the game's tags applies
+	var extreme bool
+	var filterGroups []string
+	err := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT COALESCE(array_agg(DISTINCT name::text ORDER BY name::text), '{}'), COALESCE(bool_or(extreme), FALSE)
+		FROM filter_group WHERE tags && $1::text[]`, tagNames).Scan(&filterGroups, &extreme)
+	if err != nil {
+		return nil, err
+	}
+
+	game := &types.CachedGame{
+		ID:                  fpfssGame.ID,
+		Title:               fpfssGame.Title,
+		Series:              fpfssGame.Series,
+		Developer:           fpfssGame.Developer,
+		Publisher:           fpfssGame.Publisher,
+		ReleaseDate:         fpfssGame.ReleaseDate,
+		PlayMode:            playModes,
+		Language:            languages,
+		OriginalDescription: fpfssGame.OriginalDescription,
+		Platform:            fpfssGame.Platform,
+		Extreme:             extreme,
+		FilterGroups:        filterGroups,
+		UpdatedAt:           time.Now(),
+	}
+	_, err = dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO game_cache (id, title, series, developer, publisher, release_date, play_mode, language, original_description, platform_name, extreme, filter_groups, removed, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, FALSE, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET title = $2, series = $3, developer = $4, publisher = $5, release_date = $6, play_mode = $7, language = $8,
+		original_description = $9, platform_name = $10, extreme = $11, filter_groups = $12, removed = FALSE, updated_at = CURRENT_TIMESTAMP`,
+		game.ID, game.Title, game.Series, game.Developer, game.Publisher, game.ReleaseDate, game.PlayMode, game.Language, game.OriginalDescription, game.Platform, game.Extreme, game.FilterGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save tags as well
+	_, err = dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM game_tag_cache WHERE game_id=$1", game.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range fpfssGame.Tags {
+		_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO game_tag_cache (game_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", game.ID, tag.ID)
 		if err != nil {
 			return nil, err
 		}
-		return game, nil
-	} else {
-		// Found game, check if it needs updated
-		// Update cache daily
-		if game.UpdatedAt.Before(time.Now().Add(-time.Hour * 24)) {
-			fpfssGame, err := fpfss.GetGame(gameId)
-			if err != nil {
-				return nil, err
-			}
-			if fpfssGame == nil {
-				// Game deleted from FPFSS
-				_, err = dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM game_cache WHERE id=$1", gameId)
-				if err != nil {
-					return nil, err
-				}
-				return nil, nil
-			}
-			// Update from fpfss
-			playModesArr := strings.Split(fpfssGame.PlayMode, ";")
-			for i, pm := range playModesArr {
-				playModesArr[i] = strings.TrimSpace(pm)
-			}
-			playModes := &pgtype.TextArray{}
-			err = playModes.Set(playModesArr)
-			if err != nil {
-				return nil, err
-			}
-			languagesArr := strings.Split(fpfssGame.Language, ";")
-			for i, lang := range languagesArr {
-				languagesArr[i] = strings.TrimSpace(lang)
-			}
-			languages := &pgtype.TextArray{}
-			err = languages.Set(languagesArr)
-			if err != nil {
-				return nil, err
-			}
-
-			// Build tag cache
-			extreme := false
-			var filterGroups = make([]string, 0)
-			for _, tag := range fpfssGame.Tags {
-				for _, filterGroup := range constants.GetFilterGroups() {
-					if utils.StringInSlice(tag.Name, filterGroup.Tags) {
-						if filterGroup.Extreme {
-							extreme = true
-						}
-						filterGroups = append(filterGroups, filterGroup.Name)
-						break
-					}
-				}
-				_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO tag_cache (id, name, description, category) VALUES ($1, $2, $3, $4) ON CONFLICT(id) DO UPDATE SET name = $2, description = $3, category = $4, updated_at = CURRENT_TIMESTAMP", tag.ID, tag.Name, tag.Description, tag.Category)
-				if err != nil {
-					utils.LogCtx(dbs.Ctx()).Error(err)
-					return nil, err
-				}
-			}
-			filterGroups = utils.RemoveSliceDuplicates(filterGroups)
-			sort.Strings(filterGroups)
-
-			game := &types.CachedGame{
-				ID:                  fpfssGame.ID,
-				Title:               fpfssGame.Title,
-				Series:              fpfssGame.Series,
-				Developer:           fpfssGame.Developer,
-				Publisher:           fpfssGame.Publisher,
-				ReleaseDate:         fpfssGame.ReleaseDate,
-				PlayMode:            playModesArr,
-				Language:            languagesArr,
-				OriginalDescription: fpfssGame.OriginalDescription,
-				Platform:            fpfssGame.Platform,
-				Extreme:             extreme,
-				FilterGroups:        filterGroups,
-				UpdatedAt:           time.Now(),
-			}
-			_, err = dbs.Tx().Exec(dbs.Ctx(), "UPDATE game_cache SET title=$2, series=$3, developer=$4, publisher=$5, release_date=$6, play_mode=$7, language=$8, original_description=$9, platform_name=$10, updated_at=CURRENT_TIMESTAMP WHERE id=$1",
-				game.ID, game.Title, game.Series, game.Developer, game.Publisher, game.ReleaseDate, playModes, languages, game.OriginalDescription, game.Platform)
-			if err != nil {
-				return nil, err
-			}
-			return game, nil
-		} else {
-			return game, nil
-		}
 	}
+
+	return game, nil
 }
 
 func (d *postgresDAL) SavePlaylist(dbs PGDBSession, uid string, playlist *types.Playlist, fpfss types.IFpfss) error {
@@ -789,8 +963,8 @@ func (d *postgresDAL) SavePlaylist(dbs PGDBSession, uid string, playlist *types.
 	sort.Strings(filterGroups)
 
 	if playlist.ID == 0 {
-		err = dbs.Tx().QueryRow(dbs.Ctx(), "INSERT INTO playlist (name, total_games, description, author_id, icon, public, extreme, filter_groups, library) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
-			playlist.Name, playlist.TotalGames, playlist.Description, uid, playlist.Icon, playlist.Public, extreme, filterGroups, playlist.Library).Scan(&playlist.ID)
+		err = dbs.Tx().QueryRow(dbs.Ctx(), "INSERT INTO playlist (name, total_games, description, author_id, icon, public, extreme, filter_groups, library, forked_from) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
+			playlist.Name, playlist.TotalGames, playlist.Description, uid, playlist.Icon, playlist.Public, extreme, filterGroups, playlist.Library, playlist.ForkedFrom).Scan(&playlist.ID)
 		if err != nil {
 			return err
 		}
@@ -807,12 +981,17 @@ func (d *postgresDAL) SavePlaylist(dbs PGDBSession, uid string, playlist *types.
 		return err
 	}
 
-	for _, game := range playlist.Games {
-		_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO playlist_game (playlist_id, game_id, notes) VALUES ($1, $2, $3)", playlist.ID, game.GameID, game.Notes)
+	for i, game := range playlist.Games {
+		_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO playlist_game (playlist_id, game_id, notes, position) VALUES ($1, $2, $3, $4)", playlist.ID, game.GameID, game.Notes, i)
 		if err != nil {
 			return err
 		}
 	}
+
+	_, err = dbs.Tx().Exec(dbs.Ctx(), playlistSearchVectorSQL+"p.id = $1", playlist.ID)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1153,6 +1332,17 @@ func (d *postgresDAL) SearchGotdSuggestions(dbs PGDBSession, query *types.GotdSu
 	var total int64
 
 	builder := NewSqlBuilder("SELECT id, game_id, author_id, anonymous, description, suggested_date, created_at FROM gotd_suggestion")
+	if query.GameID != "" {
+		builder.Where("game_id=$1", query.GameID)
+	}
+	if query.Filter != nil {
+		if query.Filter.HideExtreme {
+			builder.Where("NOT EXISTS (SELECT 1 FROM game_cache g WHERE g.id = gotd_suggestion.game_id AND g.extreme)")
+		}
+		if len(query.Filter.HiddenFilterGroups) > 0 {
+			builder.Where("NOT EXISTS (SELECT 1 FROM game_cache g WHERE g.id = gotd_suggestion.game_id AND g.filter_groups && $1::text[])", query.Filter.HiddenFilterGroups)
+		}
+	}
 	builder.Limit(query.PageSize)
 	builder.Offset((query.Page - 1) * query.PageSize)
 	builder.OrderBy(query.OrderBy, query.OrderDirection, []string{"created_at", "suggested_date"})
@@ -1228,40 +1418,111 @@ func (d *postgresDAL) SearchGotdSuggestions(dbs PGDBSession, query *types.GotdSu
 }
 
 func (d *postgresDAL) GetGotdSuggestion(dbs PGDBSession, sugId int64, fpfss types.IFpfss) (*types.GotdSuggestionInternal, error) {
-	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, game_id, author_id, anonymous, description, suggested_date FROM gotd_suggestion WHERE id=$1", sugId)
-	var suggestion *types.GotdSuggestionInternal
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, game_id, author_id, anonymous, description, suggested_date, created_at FROM gotd_suggestion WHERE id=$1", sugId)
+	suggestion := &types.GotdSuggestionInternal{}
 	var authorID string
 	var gameID string
-	err := row.Scan(&suggestion.ID, gameID, &authorID, &suggestion.Anonymous, &suggestion.Description, &suggestion.SuggestedDate)
+	var suggestedDate sql.NullTime
+	err := row.Scan(&suggestion.ID, &gameID, &authorID, &suggestion.Anonymous, &suggestion.Description, &suggestedDate, &suggestion.CreatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if suggestedDate.Valid {
+		suggestion.SuggestedDate = &suggestedDate.Time
+	}
 
 	game, err := d.GetGame(dbs, gameID, fpfss)
 	if err != nil {
 		return nil, err
 	}
+	if game == nil {
+		game = &types.CachedGame{
+			ID:      gameID,
+			Missing: true,
+		}
+	}
 	suggestion.Game = game
 
 	author, err := d.GetUser(dbs, authorID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if err != pgx.ErrNoRows {
+			return nil, err
+		}
+		author = &types.UserProfile{
+			UserID:    authorID,
+			Username:  "Deleted User",
+			AvatarURL: "",
+			Roles:     []string{},
+			UpdatedAt: time.Now(),
+		}
+	}
+	suggestion.Author = author
+
+	return suggestion, nil
+}
+
+// GetPendingGotdSuggestions returns every suggestion waiting to be scheduled, oldest first
+func (d *postgresDAL) GetPendingGotdSuggestions(dbs PGDBSession, fpfss types.IFpfss) ([]*types.GotdSuggestionInternal, error) {
+	results := make([]*types.GotdSuggestionInternal, 0)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT id, game_id, author_id, anonymous, description, suggested_date, created_at FROM gotd_suggestion ORDER BY created_at ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	gameIDs := make([]string, 0)
+	authorIDs := make([]string, 0)
+	for rows.Next() {
+		suggestion := &types.GotdSuggestionInternal{}
+		var gameID string
+		var authorID string
+		var suggestedDate sql.NullTime
+		err := rows.Scan(&suggestion.ID, &gameID, &authorID, &suggestion.Anonymous, &suggestion.Description, &suggestedDate, &suggestion.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if suggestedDate.Valid {
+			suggestion.SuggestedDate = &suggestedDate.Time
+		}
+		gameIDs = append(gameIDs, gameID)
+		authorIDs = append(authorIDs, authorID)
+		results = append(results, suggestion)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	games, err := d.GetGames(dbs, utils.RemoveSliceDuplicates(gameIDs), fpfss)
+	if err != nil {
+		return nil, err
+	}
+	gamesByID := make(map[string]*types.CachedGame)
+	for _, game := range games {
+		gamesByID[game.ID] = game
+	}
+	for i, suggestion := range results {
+		suggestion.Game = gamesByID[gameIDs[i]]
+		author, err := d.GetUser(dbs, authorIDs[i])
+		if err != nil {
+			if err != pgx.ErrNoRows {
+				return nil, err
+			}
 			author = &types.UserProfile{
-				UserID:    authorID,
+				UserID:    authorIDs[i],
 				Username:  "Deleted User",
 				AvatarURL: "",
 				Roles:     []string{},
 				UpdatedAt: time.Now(),
 			}
 		}
-		return nil, err
+		suggestion.Author = author
 	}
-	suggestion.Author = author
 
-	return suggestion, nil
+	return results, nil
 }
 
 func (d *postgresDAL) DeleteGotdSuggestion(dbs PGDBSession, uid string, sugId int64) error {
@@ -1274,22 +1535,45 @@ func (d *postgresDAL) DeleteGotdSuggestion(dbs PGDBSession, uid string, sugId in
 }
 
 func (d *postgresDAL) SaveGotdSuggestion(dbs PGDBSession, uid string, suggestion *types.GotdSuggestionInternal) error {
-	row := dbs.Tx().QueryRow(dbs.Ctx(), "INSERT INTO gotd_suggestion (game_id, author_id, anonymous, description, suggested_date) VALUES ($1, $2, $3, $4) RETURNING id",
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "INSERT INTO gotd_suggestion (game_id, author_id, anonymous, description, suggested_date) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
 		suggestion.Game.ID, uid, suggestion.Anonymous, suggestion.Description, suggestion.SuggestedDate)
 	var id int64
-	err := row.Scan(&id)
+	var createdAt time.Time
+	err := row.Scan(&id, &createdAt)
 	if err != nil {
 		return err
 	}
 	suggestion.ID = id
+	suggestion.CreatedAt = createdAt
 
 	return nil
 }
 
+// CountUserGotdSuggestions returns how many suggestions a user has waiting to be scheduled
+func (d *postgresDAL) CountUserGotdSuggestions(dbs PGDBSession, uid string) (int64, error) {
+	var count int64
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT COUNT(*) FROM gotd_suggestion WHERE author_id=$1", uid).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GotdSuggestionExists checks for an existing suggestion of the same game for the same date (or lack of one)
+func (d *postgresDAL) GotdSuggestionExists(dbs PGDBSession, gameID string, suggestedDate *time.Time) (bool, error) {
+	var exists bool
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT EXISTS(SELECT 1 FROM gotd_suggestion WHERE game_id=$1 AND suggested_date IS NOT DISTINCT FROM $2::date)",
+		gameID, suggestedDate).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
 func (d *postgresDAL) GetGotdCurrent(dbs PGDBSession, query *types.GetGotdCurrentQuery) ([]*types.GotdGame, error) {
-	base := "SELECT game_id, author, description, assigned_date FROM gotd"
+	base := "SELECT game_id, author, description, assigned_date, created_at FROM gotd"
 	if !query.ShowFuture {
-		base += " WHERE assigned_date < CURRENT_TIMESTAMP"
+		base += " WHERE assigned_date <= CURRENT_DATE"
 	}
 	base += " ORDER BY assigned_date ASC"
 	games := make([]*types.GotdGame, 0)
@@ -1307,7 +1591,8 @@ func (d *postgresDAL) GetGotdCurrent(dbs PGDBSession, query *types.GetGotdCurren
 		var author string
 		var description string
 		var assignedDate time.Time
-		err := rows.Scan(&gameID, &author, &description, &assignedDate)
+		var createdAt time.Time
+		err := rows.Scan(&gameID, &author, &description, &assignedDate, &createdAt)
 		if err != nil {
 			return nil, err
 		}
@@ -1316,12 +1601,50 @@ func (d *postgresDAL) GetGotdCurrent(dbs PGDBSession, query *types.GetGotdCurren
 			Author:       author,
 			Description:  description,
 			AssignedDate: assignedDate,
+			CreatedAt:    createdAt,
 		})
 	}
 
 	return games, nil
 }
 
+// GetGotdByDate returns the game assigned to the given date, or nil if the date is free
+func (d *postgresDAL) GetGotdByDate(dbs PGDBSession, date string) (*types.GotdGame, error) {
+	row := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT game_id, author, description, assigned_date, created_at FROM gotd WHERE assigned_date=$1", date)
+
+	game := &types.GotdGame{}
+	err := row.Scan(&game.ID, &game.Author, &game.Description, &game.AssignedDate, &game.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// GetGotdHistoryForGame returns every date the game has been, or is going to be, Game of the Day
+func (d *postgresDAL) GetGotdHistoryForGame(dbs PGDBSession, gameID string) ([]*types.GotdGame, error) {
+	games := make([]*types.GotdGame, 0)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT game_id, author, description, assigned_date, created_at FROM gotd WHERE game_id=$1 ORDER BY assigned_date ASC", gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		game := &types.GotdGame{}
+		err := rows.Scan(&game.ID, &game.Author, &game.Description, &game.AssignedDate, &game.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
 func (d *postgresDAL) AssignGotd(dbs PGDBSession, uid string, sugId int64, date string, fpfss types.IFpfss) error {
 	suggestion, err := d.GetGotdSuggestion(dbs, sugId, fpfss)
 	if err != nil {
@@ -1332,23 +1655,731 @@ func (d *postgresDAL) AssignGotd(dbs PGDBSession, uid string, sugId int64, date
 	}
 
 	authorName := "Anonymous"
-	if !suggestion.Anonymous && suggestion.Author.Username == "Deleted User" {
+	if !suggestion.Anonymous {
 		authorName = suggestion.Author.Username
 	}
 
-	_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO gotd (game_id, author, description, assigned_date) VALUES ($1, $2, $3, $4)",
-		suggestion.Game.ID, authorName, suggestion.Description, date)
+	_, err = dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO gotd (game_id, author, description, assigned_date, assigned_by) VALUES ($1, $2, $3, $4, $5)",
+		suggestion.Game.ID, authorName, suggestion.Description, date, uid)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return d.touchGotdSchedule(dbs)
+}
+
+// SaveGotd inserts a game directly into the schedule, replacing whatever was assigned to that date
+func (d *postgresDAL) SaveGotd(dbs PGDBSession, uid string, game *types.GotdGame) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO gotd (game_id, author, description, assigned_date, assigned_by) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (assigned_date) DO UPDATE SET game_id = $1, author = $2, description = $3, assigned_by = $5`,
+		game.ID, game.Author, game.Description, game.AssignedDate, uid)
+	if err != nil {
+		return err
+	}
+	return d.touchGotdSchedule(dbs)
 }
 
+// UnassignGotd clears the given date, returns pgx.ErrNoRows if nothing was assigned to it
 func (d *postgresDAL) UnassignGotd(dbs PGDBSession, uid string, date string) error {
-	_, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM gotd WHERE assigned_date=$1", date)
+	tag, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM gotd WHERE assigned_date=$1", date)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return d.touchGotdSchedule(dbs)
+}
+
+// touchGotdSchedule records that the schedule changed, so deletions and overwrites still move gotd.json's Last-Modified
+func (d *postgresDAL) touchGotdSchedule(dbs PGDBSession) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "UPDATE gotd_schedule SET updated_at = NOW()")
+	return err
+}
+
+// GetGotdScheduleUpdatedAt returns when the schedule was last assigned to, overwritten or cleared
+func (d *postgresDAL) GetGotdScheduleUpdatedAt(dbs PGDBSession) (time.Time, error) {
+	var updatedAt time.Time
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT updated_at FROM gotd_schedule").Scan(&updatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return updatedAt, nil
+}
+
+func (d *postgresDAL) GetFilterGroups(dbs PGDBSession) ([]*types.FilterGroup, error) {
+	groups := make([]*types.FilterGroup, 0)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT id, name, tags, extreme, created_at, updated_at FROM filter_group ORDER BY id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		group := &types.FilterGroup{}
+		err := rows.Scan(&group.ID, &group.Name, &group.Tags, &group.Extreme, &group.CreatedAt, &group.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	return groups, rows.Err()
+}
+
+func (d *postgresDAL) GetFilterGroup(dbs PGDBSession, id int64) (*types.FilterGroup, error) {
+	group := &types.FilterGroup{}
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT id, name, tags, extreme, created_at, updated_at FROM filter_group WHERE id = $1", id).
+		Scan(&group.ID, &group.Name, &group.Tags, &group.Extreme, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+func (d *postgresDAL) SaveFilterGroup(dbs PGDBSession, group *types.FilterGroup) error {
+	if group.ID == 0 {
+		return dbs.Tx().QueryRow(dbs.Ctx(), "INSERT INTO filter_group (name, tags, extreme) VALUES ($1, $2, $3) RETURNING id, created_at, updated_at",
+			group.Name, group.Tags, group.Extreme).Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+	}
+	return dbs.Tx().QueryRow(dbs.Ctx(), "UPDATE filter_group SET name = $2, tags = $3, extreme = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING created_at, updated_at",
+		group.ID, group.Name, group.Tags, group.Extreme).Scan(&group.CreatedAt, &group.UpdatedAt)
+}
+
+func (d *postgresDAL) DeleteFilterGroup(dbs PGDBSession, id int64) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM filter_group WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecomputeFilterGroups rebuilds the filter groups of every cached game carrying one of the tags or already in one
+// of the named groups, then of every playlist containing a changed game. Returns the number of games and playlists changed.
+func (d *postgresDAL) RecomputeFilterGroups(dbs PGDBSession, tags []string, groupNames []string) (int64, int64, error) {
+	// Games without cached tags are left alone until the refresher fetches their tags
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `WITH computed AS (
+			SELECT g.id,
+				COALESCE(array_agg(DISTINCT fg.name::text ORDER BY fg.name::text) FILTER (WHERE fg.id IS NOT NULL), '{}') AS filter_groups,
+				COALESCE(bool_or(fg.extreme), FALSE) AS extreme
+			FROM game_cache g
+			JOIN game_tag_cache gt ON gt.game_id = g.id
+			JOIN tag_cache t ON t.id = gt.tag_id
+			LEFT JOIN filter_group fg ON t.name::text = ANY(fg.tags)
+			WHERE g.id IN (
+				SELECT gt2.game_id::text FROM game_tag_cache gt2 JOIN tag_cache t2 ON t2.id = gt2.tag_id WHERE t2.name::text = ANY($1)
+				UNION
+				SELECT id FROM game_cache WHERE filter_groups && $2::text[]
+			)
+			GROUP BY g.id
+		)
+		UPDATE game_cache g SET filter_groups = c.filter_groups, extreme = c.extreme
+		FROM computed c
+		WHERE g.id = c.id AND (g.filter_groups, g.extreme) IS DISTINCT FROM (c.filter_groups, c.extreme)
+		RETURNING g.id`, tags, groupNames)
+	if err != nil {
+		return 0, 0, err
+	}
+	gameIDs := make([]string, 0)
+	for rows.Next() {
+		var id string
+		err := rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		gameIDs = append(gameIDs, id)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, 0, rows.Err()
+	}
+	if len(gameIDs) == 0 {
+		return 0, 0, nil
+	}
+
+	res, err := dbs.Tx().Exec(dbs.Ctx(), `WITH computed AS (
+			SELECT p.id,
+				COALESCE(array_agg(DISTINCT fg ORDER BY fg) FILTER (WHERE fg IS NOT NULL), '{}') AS filter_groups,
+				COALESCE(bool_or(g.extreme), FALSE) AS extreme
+			FROM playlist p
+			JOIN playlist_game pg ON pg.playlist_id = p.id
+			LEFT JOIN game_cache g ON g.id = pg.game_id
+			LEFT JOIN LATERAL unnest(g.filter_groups) AS fg ON TRUE
+			WHERE p.id IN (SELECT playlist_id FROM playlist_game WHERE game_id = ANY($1))
+			GROUP BY p.id
+		)
+		UPDATE playlist p SET filter_groups = c.filter_groups, extreme = c.extreme
+		FROM computed c
+		WHERE p.id = c.id AND (p.filter_groups, p.extreme) IS DISTINCT FROM (c.filter_groups, c.extreme)`, gameIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int64(len(gameIDs)), res.RowsAffected(), nil
+}
+
+func (d *postgresDAL) GetUserFilterPreferences(dbs PGDBSession, uid string) (*types.UserFilterPreferences, error) {
+	prefs := &types.UserFilterPreferences{}
+	err := dbs.Tx().QueryRow(dbs.Ctx(), "SELECT hidden_filter_groups FROM user_filter_preference WHERE uid = $1", uid).Scan(&prefs.HiddenFilterGroups)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (d *postgresDAL) SaveUserFilterPreferences(dbs PGDBSession, uid string, hiddenFilterGroups []int64) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO user_filter_preference (uid, hidden_filter_groups) VALUES ($1, $2)
+		ON CONFLICT (uid) DO UPDATE SET hidden_filter_groups = $2, updated_at = CURRENT_TIMESTAMP`, uid, hiddenFilterGroups)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreatePlaylistRevision records the playlist as it was just saved, numbering revisions per playlist
+func (d *postgresDAL) CreatePlaylistRevision(dbs PGDBSession, uid string, playlist *types.Playlist) error {
+	games := playlist.Games
+	if games == nil {
+		games = make([]types.LauncherPlaylistGame, 0)
+	}
+	gamesData, err := json.Marshal(games)
+	if err != nil {
+		return err
+	}
+	// Lock the playlist row so concurrent saves can't claim the same revision number
+	_, err = dbs.Tx().Exec(dbs.Ctx(), "SELECT id FROM playlist WHERE id = $1 FOR UPDATE", playlist.ID)
+	if err != nil {
+		return err
+	}
+	_, err = dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO playlist_revision (playlist_id, revision, name, description, library, icon, public, games, created_by)
+		VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM playlist_revision WHERE playlist_id = $1), $2, $3, $4, $5, $6, $7::jsonb, $8)`,
+		playlist.ID, playlist.Name, playlist.Description, playlist.Library, playlist.Icon, playlist.Public, string(gamesData), uid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPlaylistRevisions lists a playlist's revisions, newest first
+func (d *postgresDAL) GetPlaylistRevisions(dbs PGDBSession, playlistID int64) ([]*types.PlaylistRevisionInfo, error) {
+	revisions := make([]*types.PlaylistRevisionInfo, 0)
+	creatorIDs := make([]string, 0)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT revision, name, jsonb_array_length(games), created_by, created_at
+		FROM playlist_revision WHERE playlist_id = $1 ORDER BY revision DESC`, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		revision := &types.PlaylistRevisionInfo{}
+		var createdBy string
+		err := rows.Scan(&revision.Revision, &revision.Name, &revision.TotalGames, &createdBy, &revision.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+		creatorIDs = append(creatorIDs, createdBy)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	users := make(map[string]*types.UserProfile)
+	for i, revision := range revisions {
+		user, ok := users[creatorIDs[i]]
+		if !ok {
+			user, err = d.GetUser(dbs, creatorIDs[i])
+			if err != nil {
+				if err != pgx.ErrNoRows {
+					return nil, err
+				}
+				user = &types.UserProfile{
+					UserID:    creatorIDs[i],
+					Username:  "Deleted User",
+					AvatarURL: "",
+					Roles:     []string{},
+					UpdatedAt: time.Now(),
+				}
+			}
+			users[creatorIDs[i]] = user
+		}
+		revision.CreatedBy = user
+	}
+
+	return revisions, nil
+}
+
+func (d *postgresDAL) GetPlaylistRevision(dbs PGDBSession, playlistID int64, revision int64) (*types.PlaylistRevision, error) {
+	res := &types.PlaylistRevision{}
+	var icon sql.NullString
+	var gamesData []byte
+	var createdBy string
+	err := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT id, playlist_id, revision, name, description, library, icon, public, games, created_by, created_at
+		FROM playlist_revision WHERE playlist_id = $1 AND revision = $2`, playlistID, revision).
+		Scan(&res.ID, &res.PlaylistID, &res.Revision, &res.Name, &res.Description, &res.Library, &icon, &res.Public, &gamesData, &createdBy, &res.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	res.Icon = icon.String
+	err = json.Unmarshal(gamesData, &res.Games)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := d.GetUser(dbs, createdBy)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			return nil, err
+		}
+		user = &types.UserProfile{
+			UserID:    createdBy,
+			Username:  "Deleted User",
+			AvatarURL: "",
+			Roles:     []string{},
+			UpdatedAt: time.Now(),
+		}
+	}
+	res.CreatedBy = user
+
+	return res, nil
+}
+
+func (d *postgresDAL) GetPlaylistCollaborators(dbs PGDBSession, playlistID int64) ([]*types.PlaylistCollaborator, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT playlist_id, uid, role, invited_by, accepted_at, created_at
+		FROM playlist_collaborator WHERE playlist_id = $1 ORDER BY created_at ASC`, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	return d.readPlaylistCollaborators(dbs, rows)
+}
+
+// GetUserPlaylistInvites returns the collaborator invites the user hasn't accepted yet
+func (d *postgresDAL) GetUserPlaylistInvites(dbs PGDBSession, uid string) ([]*types.PlaylistCollaborator, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT playlist_id, uid, role, invited_by, accepted_at, created_at
+		FROM playlist_collaborator WHERE uid = $1 AND accepted_at IS NULL ORDER BY created_at DESC`, uid)
+	if err != nil {
+		return nil, err
+	}
+	return d.readPlaylistCollaborators(dbs, rows)
+}
+
+func (d *postgresDAL) GetPlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) (*types.PlaylistCollaborator, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT playlist_id, uid, role, invited_by, accepted_at, created_at
+		FROM playlist_collaborator WHERE playlist_id = $1 AND uid = $2`, playlistID, uid)
+	if err != nil {
+		return nil, err
+	}
+	collaborators, err := d.readPlaylistCollaborators(dbs, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(collaborators) == 0 {
+		return nil, nil
+	}
+	return collaborators[0], nil
+}
+
+// SavePlaylistCollaborator invites a user, or changes the role of an existing collaborator without resetting acceptance
+func (d *postgresDAL) SavePlaylistCollaborator(dbs PGDBSession, collaborator *types.PlaylistCollaborator) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), `INSERT INTO playlist_collaborator (playlist_id, uid, role, invited_by) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (playlist_id, uid) DO UPDATE SET role = $3`,
+		collaborator.PlaylistID, collaborator.User.UserID, collaborator.Role, collaborator.InvitedBy)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *postgresDAL) AcceptPlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "UPDATE playlist_collaborator SET accepted_at = CURRENT_TIMESTAMP WHERE playlist_id = $1 AND uid = $2 AND accepted_at IS NULL", playlistID, uid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *postgresDAL) DeletePlaylistCollaborator(dbs PGDBSession, playlistID int64, uid string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM playlist_collaborator WHERE playlist_id = $1 AND uid = $2", playlistID, uid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *postgresDAL) readPlaylistCollaborators(dbs PGDBSession, rows pgx.Rows) ([]*types.PlaylistCollaborator, error) {
+	collaborators := make([]*types.PlaylistCollaborator, 0)
+	for rows.Next() {
+		collaborator := &types.PlaylistCollaborator{User: &types.UserProfile{}}
+		var acceptedAt sql.NullTime
+		err := rows.Scan(&collaborator.PlaylistID, &collaborator.User.UserID, &collaborator.Role, &collaborator.InvitedBy, &acceptedAt, &collaborator.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if acceptedAt.Valid {
+			collaborator.AcceptedAt = &acceptedAt.Time
+		}
+		collaborators = append(collaborators, collaborator)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	for _, collaborator := range collaborators {
+		user, err := d.GetUser(dbs, collaborator.User.UserID)
+		if err != nil {
+			if err != pgx.ErrNoRows {
+				return nil, err
+			}
+			user = &types.UserProfile{
+				UserID:    collaborator.User.UserID,
+				Username:  "Deleted User",
+				AvatarURL: "",
+				Roles:     []string{},
+				UpdatedAt: time.Now(),
+			}
+		}
+		collaborator.User = user
+	}
+	return collaborators, nil
+}
+
+// GetPopularPlaylistForks returns the playlist's public forks, the most liked first
+func (d *postgresDAL) GetPopularPlaylistForks(dbs PGDBSession, id int64, limit int64) ([]*types.PlaylistInfo, error) {
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT id, name, total_games, description, author_id, icon, library, public, extreme, filter_groups, forked_from, "+playlistForkCountColumn+` AS fork_count, like_count, created_at, updated_at
+		FROM playlist WHERE forked_from = $1 AND public = true ORDER BY like_count DESC, fork_count DESC, updated_at DESC LIMIT $2`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	forks := make([]*types.PlaylistInfo, 0)
+	for rows.Next() {
+		fork := &types.PlaylistInfo{Author: &types.UserProfile{}}
+		err := rows.Scan(&fork.ID, &fork.Name, &fork.TotalGames, &fork.Description, &fork.Author.UserID, &fork.Icon, &fork.Library, &fork.Public,
+			&fork.Extreme, &fork.FilterGroups, &fork.ForkedFrom, &fork.ForkCount, &fork.Likes, &fork.CreatedAt, &fork.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		forks = append(forks, fork)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	rows.Close()
+
+	for _, fork := range forks {
+		author, err := d.GetUser(dbs, fork.Author.UserID)
+		if err != nil {
+			if err != pgx.ErrNoRows {
+				return nil, err
+			}
+			author = &types.UserProfile{
+				UserID:    fork.Author.UserID,
+				Username:  "Deleted User",
+				AvatarURL: "",
+				Roles:     []string{},
+				UpdatedAt: time.Now(),
+			}
+		}
+		fork.Author = author
+	}
+
+	return forks, nil
+}
+
+// LikePlaylist records the user's like, reporting false if they had already liked the playlist
+func (d *postgresDAL) LikePlaylist(dbs PGDBSession, id int64, uid string) (bool, error) {
+	res, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO playlist_like (playlist_id, uid) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, uid)
+	if err != nil {
+		return false, err
+	}
+	if res.RowsAffected() == 0 {
+		return false, nil
+	}
+	_, err = dbs.Tx().Exec(dbs.Ctx(), "UPDATE playlist SET like_count = like_count + 1 WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnlikePlaylist removes the user's like, reporting false if they hadn't liked the playlist
+func (d *postgresDAL) UnlikePlaylist(dbs PGDBSession, id int64, uid string) (bool, error) {
+	res, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM playlist_like WHERE playlist_id = $1 AND uid = $2", id, uid)
+	if err != nil {
+		return false, err
+	}
+	if res.RowsAffected() == 0 {
+		return false, nil
+	}
+	_, err = dbs.Tx().Exec(dbs.Ctx(), "UPDATE playlist SET like_count = like_count - 1 WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (d *postgresDAL) BookmarkPlaylist(dbs PGDBSession, id int64, uid string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "INSERT INTO playlist_bookmark (playlist_id, uid) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, uid)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *postgresDAL) UnbookmarkPlaylist(dbs PGDBSession, id int64, uid string) error {
+	_, err := dbs.Tx().Exec(dbs.Ctx(), "DELETE FROM playlist_bookmark WHERE playlist_id = $1 AND uid = $2", id, uid)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// GetPlaylistReactions returns the playlist's like count and whether the user has liked and bookmarked it
+func (d *postgresDAL) GetPlaylistReactions(dbs PGDBSession, id int64, uid string) (*types.PlaylistReactions, error) {
+	reactions := &types.PlaylistReactions{}
+	err := dbs.Tx().QueryRow(dbs.Ctx(), `SELECT like_count,
+		EXISTS (SELECT 1 FROM playlist_like WHERE playlist_id = $1 AND uid = $2),
+		EXISTS (SELECT 1 FROM playlist_bookmark WHERE playlist_id = $1 AND uid = $2)
+		FROM playlist WHERE id = $1`, id, uid).Scan(&reactions.Likes, &reactions.Liked, &reactions.Bookmarked)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// GetGamePlaylists returns the public playlists containing the game that the filter doesn't hide, most liked first,
+// along with how many there are in total
+func (d *postgresDAL) GetGamePlaylists(dbs PGDBSession, gameID string, filter *types.ContentFilter, limit int64) ([]*types.GamePlaylistEntry, int64, error) {
+	hideExtreme, hiddenGroups := contentFilterArgs(filter)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT p.id, p.name, p.total_games, p.description, p.author_id, p.icon, p.library, p.public, p.extreme,
+			p.filter_groups, p.forked_from, p.like_count, p.created_at, p.updated_at, COALESCE(pg.notes, ''), pg.position, COUNT(*) OVER ()
+		FROM playlist_game pg
+		JOIN playlist p ON p.id = pg.playlist_id
+		WHERE pg.game_id = $1 AND p.public = true AND NOT ($2 AND p.extreme) AND NOT (p.filter_groups && $3::text[])
+		ORDER BY p.like_count DESC, p.updated_at DESC
+		LIMIT $4`, gameID, hideExtreme, hiddenGroups, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]*types.GamePlaylistEntry, 0)
+	var total int64
+	for rows.Next() {
+		entry := &types.GamePlaylistEntry{Playlist: &types.PlaylistInfo{Author: &types.UserProfile{}}}
+		p := entry.Playlist
+		err := rows.Scan(&p.ID, &p.Name, &p.TotalGames, &p.Description, &p.Author.UserID, &p.Icon, &p.Library, &p.Public, &p.Extreme,
+			&p.FilterGroups, &p.ForkedFrom, &p.Likes, &p.CreatedAt, &p.UpdatedAt, &entry.Notes, &entry.Position, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+	if rows.Err() != nil {
+		return nil, 0, rows.Err()
+	}
+	rows.Close()
+
+	for _, entry := range entries {
+		author, err := d.GetUser(dbs, entry.Playlist.Author.UserID)
+		if err != nil {
+			if err != pgx.ErrNoRows {
+				return nil, 0, err
+			}
+			author = &types.UserProfile{
+				UserID:    entry.Playlist.Author.UserID,
+				Username:  "Deleted User",
+				AvatarURL: "",
+				Roles:     []string{},
+				UpdatedAt: time.Now(),
+			}
+		}
+		entry.Playlist.Author = author
+	}
+
+	return entries, total, nil
+}
+
+// GetRelatedGames ranks other games by how many public playlists they share with the game, leaving out removed games
+// and any the filter hides
+func (d *postgresDAL) GetRelatedGames(dbs PGDBSession, gameID string, filter *types.ContentFilter, limit int64) ([]*types.RelatedGame, error) {
+	hideExtreme, hiddenGroups := contentFilterArgs(filter)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), `SELECT other.game_id, COUNT(*) AS shared
+		FROM playlist_game self
+		JOIN playlist p ON p.id = self.playlist_id AND p.public = true
+		JOIN playlist_game other ON other.playlist_id = self.playlist_id AND other.game_id <> self.game_id
+		JOIN game_cache g ON g.id = other.game_id
+		WHERE self.game_id = $1 AND NOT g.removed AND NOT ($2 AND g.extreme) AND NOT (g.filter_groups && $3::text[])
+		GROUP BY other.game_id
+		ORDER BY shared DESC, other.game_id
+		LIMIT $4`, gameID, hideExtreme, hiddenGroups, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	related := make([]*types.RelatedGame, 0)
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		entry := &types.RelatedGame{}
+		err := rows.Scan(&id, &entry.SharedPlaylists)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		related = append(related, entry)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return related, nil
+	}
+	gameRows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT "+GameCacheColumns+" FROM game_cache WHERE id=ANY($1)", ids)
+	if err != nil {
+		return nil, err
+	}
+	games, err := readGames(gameRows)
+	if err != nil {
+		return nil, err
+	}
+	gamesByID := make(map[string]*types.CachedGame)
+	for _, game := range games {
+		gamesByID[game.ID] = game
+	}
+	for i, entry := range related {
+		entry.Game = gamesByID[ids[i]]
+	}
+
+	return related, nil
+}
+
+// contentFilterArgs turns a possibly nil filter into query arguments that hide nothing when there is no filter
+func contentFilterArgs(filter *types.ContentFilter) (bool, []string) {
+	if filter == nil {
+		return false, []string{}
+	}
+	hiddenGroups := filter.HiddenFilterGroups
+	if hiddenGroups == nil {
+		hiddenGroups = []string{}
+	}
+	return filter.HideExtreme, hiddenGroups
+}
+
+// SearchGames searches the local game cache, leaving out removed games and any the filter hides
+func (d *postgresDAL) SearchGames(dbs PGDBSession, query *types.GameSearchQuery) ([]*types.CachedGame, int64, error) {
+	builder := NewSqlBuilder("SELECT " + GameCacheColumns + " FROM game_cache")
+	builder.Where("removed=false")
+	for _, field := range []struct {
+		column string
+		value  string
+	}{
+		{"title", query.Title},
+		{"developer", query.Developer},
+		{"publisher", query.Publisher},
+		{"series", query.Series},
+	} {
+		if field.value != "" {
+			builder.Where("lower("+field.column+"::text) LIKE $1 ESCAPE '\\'", "%"+escapeLike(strings.ToLower(field.value))+"%")
+		}
+	}
+	if query.Platform != "" {
+		builder.Where("platform_name=$1", query.Platform)
+	}
+	if query.Tag != "" {
+		builder.Where("id IN (SELECT gt.game_id::text FROM game_tag_cache gt JOIN tag_cache t ON t.id = gt.tag_id WHERE t.name=$1)", query.Tag)
+	}
+	if query.FilterGroup != "" {
+		builder.Where("$1 = ANY(filter_groups)", query.FilterGroup)
+	}
+	if query.Filter != nil {
+		if query.Filter.HideExtreme {
+			builder.Where("extreme=false")
+		}
+		if len(query.Filter.HiddenFilterGroups) > 0 {
+			builder.Where("NOT (filter_groups && $1::text[])", query.Filter.HiddenFilterGroups)
+		}
+	}
+	builder.Limit(query.PageSize)
+	builder.Offset((query.Page - 1) * query.PageSize)
+	builder.OrderBy("title", "asc", []string{"title"})
+
+	rows, err := dbs.Tx().Query(dbs.Ctx(), builder.Build(0), builder.Arguments()...)
+	if err != nil {
+		return nil, 0, err
+	}
+	games, err := readGames(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if query.IncludeTotal {
+		builder.SetBase("SELECT COUNT(*) FROM game_cache")
+		err = dbs.Tx().QueryRow(dbs.Ctx(), builder.Count(0), builder.ArgumentsCount()...).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return games, total, nil
+}
+
+// AutocompleteGames finds titles starting with or similar to the text, prefix matches first and then by trigram similarity
+func (d *postgresDAL) AutocompleteGames(dbs PGDBSession, text string, filter *types.ContentFilter, limit int64) ([]*types.CachedGame, error) {
+	hideExtreme, hiddenGroups := contentFilterArgs(filter)
+	text = strings.ToLower(text)
+	rows, err := dbs.Tx().Query(dbs.Ctx(), "SELECT "+GameCacheColumns+` FROM game_cache
+		WHERE removed = false AND (lower(title::text) LIKE $5 ESCAPE '\' OR lower(title::text) % $1)
+			AND NOT ($2 AND extreme) AND NOT (filter_groups && $3::text[])
+		ORDER BY lower(title::text) LIKE $5 ESCAPE '\' DESC, similarity(lower(title::text), $1) DESC, title ASC
+		LIMIT $4`, text, hideExtreme, hiddenGroups, limit, escapeLike(text)+"%")
+	if err != nil {
+		return nil, err
+	}
+	return readGames(rows)
+}
+
+// likeEscaper escapes LIKE wildcards and the escape character itself, for use with ESCAPE '\'
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
+// escapeLike makes user input match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// readGames reads every game_cache row selected with GameCacheColumns, closing rows
+func readGames(rows pgx.Rows) ([]*types.CachedGame, error) {
+	defer rows.Close()
+	games := make([]*types.CachedGame, 0)
+	for rows.Next() {
+		game, err := ReadGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return games, nil
+}
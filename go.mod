@@ -12,8 +12,6 @@ require (
 	golang.org/x/text v0.14.0
 )
 
-require github.com/jackc/pgx v3.6.2+incompatible // indirect
-
 require (
 	github.com/felixge/httpsnoop v1.0.4
 	github.com/gemnasium/logrus-graylog-hook/v3 v3.2.0
@@ -24,7 +22,7 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
 	golang.org/x/crypto v0.15.0 // indirect
-	golang.org/x/sync v0.5.0 // indirect
+	golang.org/x/sync v0.5.0
 	golang.org/x/sys v0.14.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"mime"
@@ -16,9 +18,11 @@ import (
 	"github.com/FlashpointProject/CommunityWebsite/logging"
 	"github.com/FlashpointProject/CommunityWebsite/service"
 	"github.com/FlashpointProject/CommunityWebsite/transport"
+	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/securecookie"
+	"github.com/sirupsen/logrus"
 
 	"github.com/joho/godotenv"
 )
@@ -52,13 +56,22 @@ func main() {
 	pgdb := database.OpenPostgresDB(l, conf)
 	defer pgdb.Close()
 
-	fpfss, err := transport.NewFpfss(conf.OauthConfig, conf.FpfssApiUrl)
-	if err != nil {
-		l.WithError(err).Fatalln("failed to connect to fpfss")
+	var fpfss types.IFpfss
+	if conf.FpfssSource == config.FpfssSourceFile {
+		fpfss, err = transport.NewFileFpfss(conf.FpfssFilePath)
+		if err != nil {
+			l.WithError(err).Fatalln("failed to load fpfss file")
+		}
+		l.Infof("using fpfss data from %s", conf.FpfssFilePath)
+	} else {
+		fpfss, err = transport.NewFpfss(conf.OauthConfig, conf.FpfssApiUrl)
+		if err != nil {
+			l.WithError(err).Fatalln("failed to connect to fpfss")
+		}
 	}
 	app := &transport.App{
 		Conf:    conf,
-		Service: service.NewService(pgdb, conf.SessionExpirationSeconds),
+		Service: service.NewService(pgdb, conf.SessionExpirationSeconds, conf.SessionSecretKey, conf.GotdMaxOpenSuggestions),
 		CC: utils.CookieCutter{
 			Previous: securecookie.New([]byte(conf.SecurecookieHashKeyPrevious), []byte(conf.SecurecookieBlockKeyPrevious)),
 			Current:  securecookie.New([]byte(conf.SecurecookieHashKeyCurrent), []byte(conf.SecurecookieBlockKeyPrevious)),
@@ -71,8 +84,21 @@ func main() {
 		l.WithError(err).Fatalln("failed to load roles")
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import-gotd":
+			err = importGotd(l, app, os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command '%s'", os.Args[1])
+		}
+		if err != nil {
+			l.WithError(err).Fatalln("command failed")
+		}
+		return
+	}
+
 	srv := &http.Server{
-		Handler:      logging.LogRequestHandler(l, app.Fpfss.WithFpfss(router)),
+		Handler:      logging.LogRequestHandler(l, transport.WithFpfss(app.Fpfss, router)),
 		Addr:         fmt.Sprintf("0.0.0.0:%d", conf.Port),
 		WriteTimeout: 30 * time.Second,
 		ReadTimeout:  30 * time.Second,
@@ -82,10 +108,25 @@ func main() {
 		app.ServeRouter(l, srv, router)
 	}()
 
+	workerCtx, stopWorkers := context.WithCancel(context.WithValue(context.Background(), utils.CtxKeys.Log, l))
+	defer stopWorkers()
+	go app.Service.RunGameCacheRefresher(workerCtx, app.Fpfss,
+		time.Duration(conf.GameCacheRefreshSeconds)*time.Second,
+		time.Duration(conf.GameCacheStaleSeconds)*time.Second,
+		conf.GameCacheRefreshBatchSize)
+	go app.Service.RunSessionPurger(workerCtx, time.Duration(conf.SessionPurgeSeconds)*time.Second)
+	// A dump only lists some users, syncing against it would revoke everyone else
+	if conf.FpfssSource == config.FpfssSourceFile {
+		l.Infoln("role sync is disabled when using fpfss data from a file")
+	} else {
+		go app.Service.RunRoleSync(workerCtx, app.Fpfss, time.Duration(conf.RoleSyncSeconds)*time.Second)
+	}
+
 	term := make(chan os.Signal, 1)
 	signal.Notify(term, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	<-term
 	l.Infoln("signal received, exitting")
+	stopWorkers()
 
 	l.Infoln("shutting down the server...")
 	if err := srv.Shutdown(context.Background()); err != nil {
@@ -93,3 +134,44 @@ func main() {
 	}
 
 }
+
+// importGotd loads a launcher gotd.json from disk into the schedule
+func importGotd(l *logrus.Entry, app *transport.App, args []string) error {
+	fs := flag.NewFlagSet("import-gotd", flag.ExitOnError)
+	overwrite := fs.Bool("overwrite", false, "replace games already assigned to a date")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: import-gotd [-overwrite] <gotd.json>")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var file types.GotdFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.WithValue(context.Background(), utils.CtxKeys.Log, l)
+	result, err := app.Service.ImportGotdFile(ctx, "", &file, *overwrite, app.Fpfss)
+	if err != nil {
+		return err
+	}
+
+	l.Infof("imported %d, updated %d", result.Imported, result.Updated)
+	for _, issue := range result.Conflicts {
+		l.Warnf("conflict: %s on %s - %s", issue.GameID, issue.Date, issue.Reason)
+	}
+	for _, issue := range result.UnknownGames {
+		l.Warnf("unknown game: %s on %s - %s", issue.GameID, issue.Date, issue.Reason)
+	}
+	for _, issue := range result.Invalid {
+		l.Warnf("invalid entry: %s on %s - %s", issue.GameID, issue.Date, issue.Reason)
+	}
+	return nil
+}
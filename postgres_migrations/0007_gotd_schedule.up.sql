@@ -0,0 +1,11 @@
+CREATE TABLE "gotd" (
+  "id" SERIAL PRIMARY KEY,
+  "game_id" citext NOT NULL,
+  "author" TEXT NOT NULL,
+  "description" citext NOT NULL,
+  "assigned_date" DATE NOT NULL UNIQUE,
+  "assigned_by" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX gotd_game_id_idx ON gotd(game_id);
@@ -0,0 +1,2 @@
+DROP INDEX game_cache_updated_at_idx;
+ALTER TABLE game_cache DROP COLUMN removed;
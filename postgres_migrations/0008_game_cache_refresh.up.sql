@@ -0,0 +1,3 @@
+ALTER TABLE game_cache ADD COLUMN removed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX game_cache_updated_at_idx ON game_cache(updated_at);
@@ -0,0 +1 @@
+DROP TABLE filter_group;
@@ -0,0 +1,106 @@
+CREATE TABLE filter_group (
+  id SERIAL PRIMARY KEY,
+  "name" citext NOT NULL UNIQUE,
+  tags TEXT[] NOT NULL,
+  extreme BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO filter_group ("name", extreme, tags) VALUES
+('Seizure Warning', false, ARRAY[
+    'Seizure Warning'
+  ]),
+('Pornography', true, ARRAY[
+    'Anal',
+    'Anal Insertion',
+    'BDSM',
+    'Cartoon Porn',
+    'Adult',
+    'Anilingus',
+    'Fingering',
+    'Incest',
+    'Oral',
+    'Sexual Content',
+    'Cunnilingus',
+    'Fellatio',
+    'Footjob',
+    'Handjob',
+    'Hypnosis',
+    'Infantilism',
+    'Inflation',
+    'Interspecies',
+    'Masturbation',
+    'Paizuri',
+    'Pregnancy',
+    'Sex Toys',
+    'Spanking',
+    'Tentacles',
+    'Touching',
+    'Tribadism',
+    'Urination',
+    'Vaginal',
+    'Vaginal Insertion',
+    'Futanari',
+    'Male Futanari',
+    'Gynomorph',
+    'Andromorph',
+    'Oviposition',
+    'Intersex',
+    'Breast Milking',
+    'Porn',
+    'Hentai',
+    'Group',
+    'Solo',
+    'Cannibalism',
+    'Enema',
+    'Frottage',
+    'Kabeshiri',
+    'Macrophilia',
+    'Obesity',
+    'Podophilia',
+    'Quicksand',
+    'Tickling',
+    'Weight Gain',
+    'Gloryhole',
+    'Multiple Penises',
+    'Ambiguous Penetration',
+    'Self Oral'
+  ]),
+('Violence', true, ARRAY[
+    'Gore',
+    'Strong Violence',
+    'Strong Language'
+  ]),
+('Bigotry', true, ARRAY[
+    'Homophobia',
+    'Stereotyping',
+    'Racism',
+    'Transphobia'
+  ]),
+('Pornography (Extreme)', true, ARRAY[
+    'Bestiality',
+    'Cannibalism',
+    'Enema',
+    'Fisting',
+    'Flatulence',
+    'Necrophilia',
+    'Scat',
+    'Vomit',
+    'Vore',
+    'Sexual Violence'
+  ]),
+('Otherwise Mature Topics', true, ARRAY[
+    'Drugs',
+    'Reproductive Health',
+    'Addiction',
+    'Heavy Themes',
+    'Suicide',
+    'Nudity',
+    'Moderate Language',
+    'Sexual Harassment'
+  ]);
+
+-- Games cached before tags were stored can't have their filter groups recomputed, refresh them first
+UPDATE game_cache SET updated_at = '1970-01-01'
+WHERE NOT EXISTS (SELECT 1 FROM game_tag_cache WHERE game_tag_cache.game_id = game_cache.id);
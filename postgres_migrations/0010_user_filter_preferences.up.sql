@@ -0,0 +1,5 @@
+CREATE TABLE user_filter_preference (
+  uid TEXT PRIMARY KEY REFERENCES fpcomm_user(id) ON DELETE CASCADE,
+  hidden_filter_groups INTEGER[] NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,24 @@
+CREATE TABLE playlist_revision (
+  id SERIAL PRIMARY KEY,
+  playlist_id INTEGER NOT NULL REFERENCES playlist(id) ON DELETE CASCADE,
+  revision INTEGER NOT NULL,
+  name citext NOT NULL,
+  description citext NOT NULL,
+  library TEXT NOT NULL,
+  icon TEXT,
+  public BOOLEAN NOT NULL,
+  games JSONB NOT NULL,
+  created_by TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT playlist_revision_playlist_id_revision_key UNIQUE (playlist_id, revision)
+);
+
+-- Existing playlists start their history from their current state. Games were saved by deleting and re-inserting
+-- them in list order, so physical row order is a best-effort guess at the order users saw. Updates and vacuums can
+-- move rows, so it isn't guaranteed, and game_id breaks ties to keep reruns deterministic.
+INSERT INTO playlist_revision (playlist_id, revision, name, description, library, icon, public, games, created_by, created_at)
+SELECT p.id, 1, p.name, p.description, p.library, p.icon, p.public,
+  COALESCE((SELECT jsonb_agg(jsonb_build_object('gameId', pg.game_id, 'notes', COALESCE(pg.notes, '')) ORDER BY pg.ctid, pg.game_id)
+    FROM playlist_game pg WHERE pg.playlist_id = p.id), '[]'::jsonb),
+  p.author_id, p.updated_at
+FROM playlist p;
@@ -0,0 +1,2 @@
+ALTER TABLE playlist_game DROP CONSTRAINT playlist_game_playlist_id_position_key;
+ALTER TABLE playlist_game DROP COLUMN position;
@@ -0,0 +1,12 @@
+ALTER TABLE playlist_game ADD COLUMN position INTEGER;
+
+-- Existing rows had no defined order, number them the same best-effort way their first revision did
+UPDATE playlist_game pg SET position = ordered.position
+FROM (
+  SELECT playlist_id, game_id, ROW_NUMBER() OVER (PARTITION BY playlist_id ORDER BY ctid, game_id) - 1 AS position
+  FROM playlist_game
+) ordered
+WHERE pg.playlist_id = ordered.playlist_id AND pg.game_id = ordered.game_id;
+
+ALTER TABLE playlist_game ALTER COLUMN position SET NOT NULL;
+ALTER TABLE playlist_game ADD CONSTRAINT playlist_game_playlist_id_position_key UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED;
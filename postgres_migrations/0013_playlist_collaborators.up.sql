@@ -0,0 +1,11 @@
+CREATE TABLE playlist_collaborator (
+  playlist_id INTEGER NOT NULL REFERENCES playlist(id) ON DELETE CASCADE,
+  uid TEXT NOT NULL REFERENCES fpcomm_user(id) ON DELETE CASCADE,
+  role TEXT NOT NULL CHECK (role IN ('editor', 'viewer')),
+  invited_by TEXT NOT NULL,
+  accepted_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (playlist_id, uid)
+);
+
+CREATE INDEX playlist_collaborator_uid_idx ON playlist_collaborator(uid);
@@ -0,0 +1,2 @@
+DROP INDEX playlist_forked_from_idx;
+ALTER TABLE playlist DROP COLUMN forked_from;
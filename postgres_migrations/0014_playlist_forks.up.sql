@@ -0,0 +1,3 @@
+ALTER TABLE playlist ADD COLUMN forked_from INTEGER REFERENCES playlist(id) ON DELETE SET NULL;
+
+CREATE INDEX playlist_forked_from_idx ON playlist(forked_from);
@@ -0,0 +1,3 @@
+DROP TABLE playlist_bookmark;
+DROP TABLE playlist_like;
+ALTER TABLE playlist DROP COLUMN like_count;
@@ -0,0 +1,20 @@
+ALTER TABLE playlist ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE playlist_like (
+  playlist_id INTEGER NOT NULL REFERENCES playlist(id) ON DELETE CASCADE,
+  uid TEXT NOT NULL REFERENCES fpcomm_user(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (playlist_id, uid)
+);
+
+CREATE INDEX playlist_like_uid_idx ON playlist_like(uid);
+CREATE INDEX playlist_like_created_at_idx ON playlist_like(playlist_id, created_at);
+
+CREATE TABLE playlist_bookmark (
+  playlist_id INTEGER NOT NULL REFERENCES playlist(id) ON DELETE CASCADE,
+  uid TEXT NOT NULL REFERENCES fpcomm_user(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (playlist_id, uid)
+);
+
+CREATE INDEX playlist_bookmark_uid_idx ON playlist_bookmark(uid, created_at);
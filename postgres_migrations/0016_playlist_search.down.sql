@@ -0,0 +1,5 @@
+DROP INDEX game_cache_platform_name_idx;
+DROP INDEX tag_cache_name_idx;
+DROP INDEX playlist_game_game_id_idx;
+DROP INDEX playlist_search_vector_idx;
+ALTER TABLE playlist DROP COLUMN search_vector;
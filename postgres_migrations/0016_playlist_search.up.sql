@@ -0,0 +1,19 @@
+ALTER TABLE playlist ADD COLUMN search_vector tsvector NOT NULL DEFAULT ''::tsvector;
+
+-- Playlist name and description, then the titles, developers and tags of the games in it
+UPDATE playlist p SET search_vector =
+  setweight(to_tsvector('english', p.name::text), 'A') ||
+  setweight(to_tsvector('english', p.description::text), 'B') ||
+  setweight(to_tsvector('english', COALESCE((
+    SELECT string_agg(concat_ws(' ', g.title::text, g.developer::text), ' ')
+    FROM playlist_game pg JOIN game_cache g ON g.id = pg.game_id
+    WHERE pg.playlist_id = p.id), '')), 'C') ||
+  setweight(to_tsvector('english', COALESCE((
+    SELECT string_agg(DISTINCT t.name::text, ' ')
+    FROM playlist_game pg JOIN game_tag_cache gt ON gt.game_id::text = pg.game_id JOIN tag_cache t ON t.id = gt.tag_id
+    WHERE pg.playlist_id = p.id), '')), 'D');
+
+CREATE INDEX playlist_search_vector_idx ON playlist USING GIN (search_vector);
+CREATE INDEX playlist_game_game_id_idx ON playlist_game(game_id);
+CREATE INDEX tag_cache_name_idx ON tag_cache(name);
+CREATE INDEX game_cache_platform_name_idx ON game_cache(platform_name);
@@ -0,0 +1,5 @@
+DROP INDEX game_tag_cache_tag_id_idx;
+DROP INDEX game_cache_series_trgm_idx;
+DROP INDEX game_cache_publisher_trgm_idx;
+DROP INDEX game_cache_developer_trgm_idx;
+DROP INDEX game_cache_title_trgm_idx;
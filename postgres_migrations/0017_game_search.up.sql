@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX game_cache_title_trgm_idx ON game_cache USING GIN (lower(title::text) gin_trgm_ops);
+CREATE INDEX game_cache_developer_trgm_idx ON game_cache USING GIN (lower(developer::text) gin_trgm_ops);
+CREATE INDEX game_cache_publisher_trgm_idx ON game_cache USING GIN (lower(publisher::text) gin_trgm_ops);
+CREATE INDEX game_cache_series_trgm_idx ON game_cache USING GIN (lower(series::text) gin_trgm_ops);
+CREATE INDEX game_tag_cache_tag_id_idx ON game_tag_cache(tag_id);
@@ -0,0 +1,5 @@
+DROP INDEX session_expires_at_idx;
+DROP INDEX session_uid_idx;
+DROP INDEX session_secret_idx;
+
+ALTER TABLE "session" DROP COLUMN "user_agent";
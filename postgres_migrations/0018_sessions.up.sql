@@ -0,0 +1,5 @@
+ALTER TABLE "session" ADD COLUMN "user_agent" TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX session_secret_idx ON "session" (secret);
+CREATE INDEX session_uid_idx ON "session" (uid);
+CREATE INDEX session_expires_at_idx ON "session" (expires_at);
@@ -0,0 +1,8 @@
+ALTER TABLE fpcomm_user DROP COLUMN roles_updated_at;
+
+DELETE FROM "session";
+
+DROP INDEX session_secret_hash_idx;
+ALTER TABLE "session" DROP COLUMN "rotated_at";
+ALTER TABLE "session" RENAME COLUMN "secret_hash" TO "secret";
+CREATE INDEX session_secret_idx ON "session" (secret);
@@ -0,0 +1,10 @@
+-- Existing secrets were stored in plaintext and can't be rehashed without the application key,
+-- so every session is invalidated and users log in again
+DELETE FROM "session";
+
+DROP INDEX session_secret_idx;
+ALTER TABLE "session" RENAME COLUMN "secret" TO "secret_hash";
+ALTER TABLE "session" ADD COLUMN "rotated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+CREATE UNIQUE INDEX session_secret_hash_idx ON "session" (secret_hash);
+
+ALTER TABLE fpcomm_user ADD COLUMN roles_updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
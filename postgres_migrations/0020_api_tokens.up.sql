@@ -0,0 +1,13 @@
+CREATE TABLE api_token (
+  id SERIAL PRIMARY KEY,
+  uid TEXT NOT NULL REFERENCES fpcomm_user(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  token_hash TEXT NOT NULL,
+  scopes TEXT[] NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX api_token_token_hash_idx ON api_token(token_hash);
+CREATE INDEX api_token_uid_idx ON api_token(uid);
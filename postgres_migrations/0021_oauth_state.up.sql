@@ -0,0 +1,9 @@
+CREATE TABLE oauth_state (
+  nonce TEXT PRIMARY KEY,
+  redirect_uri TEXT NOT NULL,
+  code_verifier TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX oauth_state_expires_at_idx ON oauth_state(expires_at);
@@ -0,0 +1 @@
+DROP TABLE gotd_schedule;
@@ -0,0 +1,7 @@
+-- Single row tracking when the schedule last changed, deletions included, for gotd.json's Last-Modified
+CREATE TABLE gotd_schedule (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO gotd_schedule DEFAULT VALUES;
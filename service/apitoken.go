@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+const (
+	maxApiTokensPerUser = 25
+	maxApiTokenName     = 100
+)
+
+func (s *Service) GetApiTokens(ctx context.Context, uid string) ([]*types.ApiToken, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	tokens, err := s.pgdal.GetApiTokens(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return tokens, nil
+}
+
+// CreateApiToken creates a personal API token. Only its hash is stored, the secret is returned once.
+func (s *Service) CreateApiToken(ctx context.Context, uid string, sub *types.SubmittedApiToken) (*types.CreatedApiToken, error) {
+	name := strings.TrimSpace(sub.Name)
+	if name == "" {
+		return nil, perr("name is a required field", http.StatusBadRequest)
+	}
+	if len(name) > maxApiTokenName {
+		return nil, perr(fmt.Sprintf("name must be at most %d characters", maxApiTokenName), http.StatusBadRequest)
+	}
+	scopes := utils.RemoveSliceDuplicates(sub.Scopes)
+	if len(scopes) == 0 {
+		return nil, perr("at least one scope is required", http.StatusBadRequest)
+	}
+	for _, scope := range scopes {
+		if !utils.StringInSlice(scope, constants.ApiTokenScopes()) {
+			return nil, perr(fmt.Sprintf("unknown scope '%s'", scope), http.StatusBadRequest)
+		}
+	}
+	if sub.ExpiresAt != nil && !sub.ExpiresAt.After(time.Now()) {
+		return nil, perr("expires_at must be in the future", http.StatusBadRequest)
+	}
+
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	count, err := s.pgdal.CountApiTokens(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if count >= maxApiTokensPerUser {
+		return nil, perr(fmt.Sprintf("you can have at most %d API tokens", maxApiTokensPerUser), http.StatusBadRequest)
+	}
+
+	secret, err := generateApiToken()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+
+	token := &types.ApiToken{
+		Name:      name,
+		Scopes:    scopes,
+		ExpiresAt: sub.ExpiresAt,
+	}
+	if err = s.pgdal.SaveApiToken(dbs, uid, s.hashSecret(secret), token); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return &types.CreatedApiToken{
+		Token:  token,
+		Secret: secret,
+	}, nil
+}
+
+func (s *Service) RevokeApiToken(ctx context.Context, uid string, id int64) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	found, err := s.pgdal.DeleteApiToken(dbs, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if !found {
+		return perr("token not found", http.StatusNotFound)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// GetApiTokenAuthInfo returns the owner and scopes of an API token, reporting false if it is unknown or expired
+func (s *Service) GetApiTokenAuthInfo(ctx context.Context, token string) (*types.SessionInfo, bool, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, false, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	info, ok, err := s.pgdal.GetApiTokenAuthInfo(dbs, s.hashSecret(token))
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, false, dberr(err)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	if err = s.pgdal.TouchApiToken(dbs, info.TokenID); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, false, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, false, dberr(err)
+	}
+
+	return info, true, nil
+}
+
+func generateApiToken() (string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return "", err
+	}
+	return constants.ApiTokenPrefix + secret, nil
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
@@ -12,7 +15,7 @@ type authToken struct {
 	UserID string
 }
 
-func (s *Service) SaveUser(ctx context.Context, fpfssUser *types.FPFSSProfile, ipAddr string) (*authToken, error) {
+func (s *Service) SaveUser(ctx context.Context, fpfssUser *types.FPFSSProfile, ipAddr string, userAgent string) (*authToken, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -43,7 +46,7 @@ func (s *Service) SaveUser(ctx context.Context, fpfssUser *types.FPFSSProfile, i
 		return nil, dberr(err)
 	}
 
-	if err = s.pgdal.StoreSession(dbs, authToken.Secret, fpfssUser.ID, s.sessionExpirationSeconds, ipAddr); err != nil {
+	if err = s.pgdal.StoreSession(dbs, s.hashSecret(authToken.Secret), fpfssUser.ID, s.sessionExpirationSeconds, ipAddr, userAgent); err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
 	}
@@ -56,6 +59,46 @@ func (s *Service) SaveUser(ctx context.Context, fpfssUser *types.FPFSSProfile, i
 	return authToken, nil
 }
 
+// RotateSession issues a new secret for a session whose user's roles changed since it was last issued.
+// It returns nil if a concurrent request already rotated the session.
+func (s *Service) RotateSession(ctx context.Context, session *types.SessionInfo, secret string) (*authToken, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	authToken, err := s.authTokenProvider.CreateAuthToken(session.UID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+
+	rotated, err := s.pgdal.RotateSession(dbs, session.ID, s.hashSecret(secret), s.hashSecret(authToken.Secret))
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if !rotated {
+		return nil, nil
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return authToken, nil
+}
+
+// hashSecret returns the keyed hash a session secret or API token is stored and looked up under
+func (s *Service) hashSecret(secret string) string {
+	mac := hmac.New(sha256.New, s.sessionSecretKey)
+	mac.Write([]byte(secret))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 func MapAuthToken(token *authToken) map[string]string {
 	return map[string]string{"Secret": token.Secret, "userID": token.UserID}
 }
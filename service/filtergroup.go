@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+func (s *Service) GetFilterGroups(ctx context.Context) ([]*types.FilterGroup, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	groups, err := s.pgdal.GetFilterGroups(dbs)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return groups, nil
+}
+
+// CreateFilterGroup adds a new group and applies it to every cached game and playlist carrying its tags
+func (s *Service) CreateFilterGroup(ctx context.Context, sub *types.SubmittedFilterGroup) (*types.FilterGroupRecomputeResult, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	group := &types.FilterGroup{}
+	applyFilterGroupSubmission(group, sub)
+	err = s.checkFilterGroupName(dbs, group)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pgdal.SaveFilterGroup(dbs, group)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	games, playlists, err := s.pgdal.RecomputeFilterGroups(dbs, group.Tags, []string{group.Name})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("created filter group %d, recomputed %d games and %d playlists", group.ID, games, playlists)
+	return &types.FilterGroupRecomputeResult{Group: group, Games: games, Playlists: playlists}, nil
+}
+
+// UpdateFilterGroup edits a group and recomputes every cached game and playlist affected by the old or new tags
+func (s *Service) UpdateFilterGroup(ctx context.Context, id int64, sub *types.SubmittedFilterGroup) (*types.FilterGroupRecomputeResult, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	group, err := s.pgdal.GetFilterGroup(dbs, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if group == nil {
+		return nil, perr("filter group not found", http.StatusNotFound)
+	}
+	oldName := group.Name
+	oldTags := group.Tags
+
+	applyFilterGroupSubmission(group, sub)
+	err = s.checkFilterGroupName(dbs, group)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.pgdal.SaveFilterGroup(dbs, group)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	tags := utils.RemoveSliceDuplicates(append(append([]string{}, oldTags...), group.Tags...))
+	games, playlists, err := s.pgdal.RecomputeFilterGroups(dbs, tags, []string{oldName, group.Name})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("updated filter group %d, recomputed %d games and %d playlists", group.ID, games, playlists)
+	return &types.FilterGroupRecomputeResult{Group: group, Games: games, Playlists: playlists}, nil
+}
+
+// DeleteFilterGroup removes a group and drops it from every cached game and playlist
+func (s *Service) DeleteFilterGroup(ctx context.Context, id int64) (*types.FilterGroupRecomputeResult, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	group, err := s.pgdal.GetFilterGroup(dbs, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if group == nil {
+		return nil, perr("filter group not found", http.StatusNotFound)
+	}
+
+	err = s.pgdal.DeleteFilterGroup(dbs, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	games, playlists, err := s.pgdal.RecomputeFilterGroups(dbs, group.Tags, []string{group.Name})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("deleted filter group %d, recomputed %d games and %d playlists", group.ID, games, playlists)
+	return &types.FilterGroupRecomputeResult{Group: group, Games: games, Playlists: playlists}, nil
+}
+
+// checkFilterGroupName rejects a name already used by another group, ignoring case
+func (s *Service) checkFilterGroupName(dbs database.PGDBSession, group *types.FilterGroup) error {
+	groups, err := s.pgdal.GetFilterGroups(dbs)
+	if err != nil {
+		utils.LogCtx(dbs.Ctx()).Error(err)
+		return dberr(err)
+	}
+	for _, existing := range groups {
+		if existing.ID != group.ID && strings.EqualFold(existing.Name, group.Name) {
+			return perr("a filter group with that name already exists", http.StatusConflict)
+		}
+	}
+	return nil
+}
+
+func applyFilterGroupSubmission(group *types.FilterGroup, sub *types.SubmittedFilterGroup) {
+	group.Name = strings.TrimSpace(sub.Name)
+	group.Extreme = sub.Extreme
+	group.Tags = make([]string, 0)
+	for _, tag := range sub.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			group.Tags = append(group.Tags, tag)
+		}
+	}
+	group.Tags = utils.RemoveSliceDuplicates(group.Tags)
+}
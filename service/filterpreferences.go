@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// GetFilterPreferences returns the groups the user hides, or the default of every extreme group if they never chose
+func (s *Service) GetFilterPreferences(ctx context.Context, uid string) (*types.UserFilterPreferences, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	prefs, err := s.pgdal.GetUserFilterPreferences(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if prefs != nil {
+		return prefs, nil
+	}
+
+	groups, err := s.pgdal.GetFilterGroups(dbs)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	prefs = &types.UserFilterPreferences{
+		HiddenFilterGroups: make([]int64, 0),
+		Default:            true,
+	}
+	for _, group := range groups {
+		if group.Extreme {
+			prefs.HiddenFilterGroups = append(prefs.HiddenFilterGroups, group.ID)
+		}
+	}
+
+	return prefs, nil
+}
+
+func (s *Service) SaveFilterPreferences(ctx context.Context, uid string, sub *types.SubmittedFilterPreferences) (*types.UserFilterPreferences, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	groups, err := s.pgdal.GetFilterGroups(dbs)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	known := make(map[int64]bool)
+	for _, group := range groups {
+		known[group.ID] = true
+	}
+	hidden := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, id := range sub.HiddenFilterGroups {
+		if !known[id] {
+			return nil, perr(fmt.Sprintf("unknown filter group %d", id), http.StatusBadRequest)
+		}
+		if !seen[id] {
+			seen[id] = true
+			hidden = append(hidden, id)
+		}
+	}
+
+	err = s.pgdal.SaveUserFilterPreferences(dbs, uid, hidden)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return &types.UserFilterPreferences{HiddenFilterGroups: hidden}, nil
+}
+
+// contentFilter resolves what the viewer should not see. Saved preferences always apply. Without them, extreme
+// groups are hidden unless a logged in viewer explicitly opted in to extreme content. Anonymous viewers always
+// get the safe default.
+func (s *Service) contentFilter(dbs database.PGDBSession, uid string, includeExtreme bool) (*types.ContentFilter, error) {
+	var prefs *types.UserFilterPreferences
+	if uid != "" {
+		var err error
+		prefs, err = s.pgdal.GetUserFilterPreferences(dbs, uid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if prefs == nil && includeExtreme && uid != "" {
+		return &types.ContentFilter{}, nil
+	}
+
+	groups, err := s.pgdal.GetFilterGroups(dbs)
+	if err != nil {
+		return nil, err
+	}
+	filter := &types.ContentFilter{
+		HiddenFilterGroups: make([]string, 0),
+		HideExtreme:        prefs == nil,
+	}
+	for _, group := range groups {
+		hide := group.Extreme
+		if prefs != nil {
+			hide = false
+			for _, id := range prefs.HiddenFilterGroups {
+				if id == group.ID {
+					hide = true
+					break
+				}
+			}
+		}
+		if hide {
+			filter.HiddenFilterGroups = append(filter.HiddenFilterGroups, group.Name)
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+const (
+	gamePagePlaylistsLimit   = 50
+	gamePageSuggestionsLimit = 20
+	gamePageRelatedLimit     = 12
+)
+
+// GetGameCommunityPage gathers what the community has done with a game: the public playlists featuring it,
+// its Game of the Day history, open suggestions and the games most often curated alongside it
+func (s *Service) GetGameCommunityPage(ctx context.Context, uid string, includeExtreme bool, id string, fpfss types.IFpfss) (*types.GameCommunityPage, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	game, err := s.pgdal.GetGame(dbs, id, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if game == nil {
+		return nil, perr("game not found", http.StatusNotFound)
+	}
+
+	filter, err := s.contentFilter(dbs, uid, includeExtreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if filter.Hides(game) {
+		return nil, perr("this game is hidden by your content filters", http.StatusForbidden)
+	}
+
+	page := &types.GameCommunityPage{Game: game}
+
+	page.Playlists, page.TotalPlaylists, err = s.pgdal.GetGamePlaylists(dbs, game.ID, filter, gamePagePlaylistsLimit)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	// Only past and present dates, the future schedule stays a surprise
+	history, err := s.pgdal.GetGotdHistoryForGame(dbs, game.ID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	page.GotdHistory = make([]*types.GotdGame, 0)
+	for _, entry := range history {
+		if !entry.AssignedDate.After(today) {
+			page.GotdHistory = append(page.GotdHistory, entry)
+		}
+	}
+
+	suggestions, _, err := s.pgdal.SearchGotdSuggestions(dbs, &types.GotdSuggestionsSearchQuery{
+		Page:           1,
+		PageSize:       gamePageSuggestionsLimit,
+		OrderBy:        "created_at",
+		OrderDirection: "asc",
+		GameID:         game.ID,
+	}, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	page.Suggestions = make([]*types.GotdSuggestion, 0)
+	for _, suggestion := range suggestions {
+		page.Suggestions = append(page.Suggestions, suggestion.ToExternal())
+	}
+
+	page.RelatedGames, err = s.pgdal.GetRelatedGames(dbs, game.ID, filter, gamePageRelatedLimit)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return page, nil
+}
+
+// SearchGames searches the local game cache. With the fpfss option set and nothing found locally, it searches FPFSS
+// instead and caches what it finds.
+func (s *Service) SearchGames(ctx context.Context, uid string, query *types.GameSearchQuery, fpfss types.IFpfss) (*types.GameSearchResponse, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	query.Filter, err = s.contentFilter(dbs, uid, query.Extreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	res := &types.GameSearchResponse{Source: types.GameSearchSourceCache}
+	if query.Autocomplete {
+		res.Games, err = s.pgdal.AutocompleteGames(dbs, query.Title, query.Filter, query.PageSize)
+		res.Total = int64(len(res.Games))
+	} else {
+		res.Games, res.Total, err = s.pgdal.SearchGames(dbs, query)
+	}
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if len(res.Games) > 0 || !query.Fpfss || query.Page > 1 {
+		return res, nil
+	}
+
+	fpfssGames, err := fpfss.SearchGames(ctx, &types.FpfssGameSearch{
+		Title:     query.Title,
+		Developer: query.Developer,
+		Publisher: query.Publisher,
+		Series:    query.Series,
+		Platform:  query.Platform,
+		Tag:       query.Tag,
+		Limit:     query.PageSize,
+	})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, perr("failed to search FPFSS", http.StatusBadGateway)
+	}
+	if len(fpfssGames) == 0 {
+		return res, nil
+	}
+
+	err = s.pgdal.CacheGames(dbs, fpfssGames)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	ids := make([]string, len(fpfssGames))
+	for i, game := range fpfssGames {
+		ids[i] = game.ID
+	}
+	cached, err := s.pgdal.GetGames(dbs, ids, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	// Filter groups are only known once cached, so the content filter applies afterwards
+	res.Source = types.GameSearchSourceFpfss
+	for _, game := range cached {
+		if game.Missing || (query.FilterGroup != "" && !utils.StringInSlice(query.FilterGroup, game.FilterGroups)) {
+			continue
+		}
+		if query.Filter.Hides(game) {
+			continue
+		}
+		res.Games = append(res.Games, game)
+	}
+	res.Total = int64(len(res.Games))
+
+	return res, nil
+}
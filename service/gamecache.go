@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// RunGameCacheRefresher refreshes stale game_cache rows from FPFSS every interval until ctx is cancelled
+func (s *Service) RunGameCacheRefresher(ctx context.Context, fpfss types.IFpfss, interval time.Duration, staleAfter time.Duration, batchSize int64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		refreshed, err := s.RefreshStaleGames(ctx, fpfss, staleAfter, batchSize)
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Error("failed to refresh game cache")
+		} else if refreshed > 0 {
+			utils.LogCtx(ctx).Infof("refreshed %d cached games", refreshed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshStaleGames refreshes every game older than staleAfter in batches, returning how many were refreshed
+func (s *Service) RefreshStaleGames(ctx context.Context, fpfss types.IFpfss, staleAfter time.Duration, batchSize int64) (int, error) {
+	// Anything touched after this point is fresh, which stops the loop from revisiting this run's work
+	before := time.Now().Add(-staleAfter)
+	total := 0
+	for {
+		if ctx.Err() != nil {
+			return total, nil
+		}
+		ids, err := s.getStaleGameIDs(ctx, before, batchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		// Fetch outside of any transaction so a slow FPFSS never holds a connection open
+		fpfssGames, err := fpfss.GetGames(ctx, ids)
+		if err != nil {
+			return total, err
+		}
+
+		err = s.saveRefreshedGames(ctx, ids, fpfssGames)
+		if err != nil {
+			return total, err
+		}
+		total += len(ids)
+
+		if int64(len(ids)) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (s *Service) getStaleGameIDs(ctx context.Context, before time.Time, limit int64) ([]string, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	ids, err := s.pgdal.GetStaleGameIDs(dbs, before, limit)
+	if err != nil {
+		return nil, dberr(err)
+	}
+
+	return ids, nil
+}
+
+// saveRefreshedGames stores the games FPFSS returned and marks the rest of the batch as removed
+func (s *Service) saveRefreshedGames(ctx context.Context, ids []string, fpfssGames []*types.FpfssGame) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	found := make(map[string]bool)
+	for _, game := range fpfssGames {
+		found[strings.ToLower(game.ID)] = true
+	}
+	removed := make([]string, 0)
+	for _, id := range ids {
+		if !found[strings.ToLower(id)] {
+			removed = append(removed, id)
+		}
+	}
+
+	err = s.pgdal.CacheGames(dbs, fpfssGames)
+	if err != nil {
+		return dberr(err)
+	}
+	if len(removed) > 0 {
+		err = s.pgdal.MarkGamesRemoved(dbs, removed)
+		if err != nil {
+			return dberr(err)
+		}
+	}
+
+	// Playlist search covers game titles, developers and tags, which may have changed
+	_, err = s.pgdal.RefreshGamePlaylistsSearch(dbs, ids)
+	if err != nil {
+		return dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		return dberr(err)
+	}
+
+	return nil
+}
@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
+	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/jackc/pgx/v5"
 )
 
-func (s *Service) SearchGotdSuggestions(ctx context.Context, query *types.GotdSuggestionsSearchQuery, fpfss types.IFpfss) ([]*types.GotdSuggestion, int64, error) {
+func (s *Service) SearchGotdSuggestions(ctx context.Context, uid string, query *types.GotdSuggestionsSearchQuery, fpfss types.IFpfss) ([]*types.GotdSuggestion, int64, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -15,6 +21,12 @@ func (s *Service) SearchGotdSuggestions(ctx context.Context, query *types.GotdSu
 	}
 	defer dbs.Rollback()
 
+	query.Filter, err = s.contentFilter(dbs, uid, query.Extreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, 0, dberr(err)
+	}
+
 	reports, total, err := s.pgdal.SearchGotdSuggestions(dbs, query, fpfss)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -28,3 +40,359 @@ func (s *Service) SearchGotdSuggestions(ctx context.Context, query *types.GotdSu
 
 	return externalReports, total, nil
 }
+
+func (s *Service) GetGotdCurrent(ctx context.Context, query *types.GetGotdCurrentQuery) ([]*types.GotdGame, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	games, err := s.pgdal.GetGotdCurrent(dbs, query)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return games, nil
+}
+
+// AssignGotd schedules a suggestion for the given date and removes it from the suggestion list
+func (s *Service) AssignGotd(ctx context.Context, uid string, sugId int64, date string, fpfss types.IFpfss) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	suggestion, err := s.pgdal.GetGotdSuggestion(dbs, sugId, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if suggestion == nil {
+		return perr("suggestion not found", http.StatusNotFound)
+	}
+	if suggestion.Game.Missing || suggestion.Game.Removed {
+		return perr("the suggested game has been removed from Flashpoint", http.StatusBadRequest)
+	}
+
+	existing, err := s.pgdal.GetGotdByDate(dbs, date)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if existing != nil {
+		return perr(fmt.Sprintf("%s is already assigned", date), http.StatusConflict)
+	}
+
+	err = s.pgdal.AssignGotd(dbs, uid, sugId, date, fpfss)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return perr(fmt.Sprintf("%s is already assigned", date), http.StatusConflict)
+		}
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	err = s.pgdal.DeleteGotdSuggestion(dbs, uid, sugId)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+func (s *Service) UnassignGotd(ctx context.Context, uid string, date string) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	err = s.pgdal.UnassignGotd(dbs, uid, date)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return perr(fmt.Sprintf("nothing is assigned to %s", date), http.StatusNotFound)
+		}
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// SubmitGotdSuggestion validates and stores a user's suggestion
+func (s *Service) SubmitGotdSuggestion(ctx context.Context, uid string, suggestion *types.GotdSuggestionInternal, fpfss types.IFpfss) (*types.GotdSuggestion, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	openSuggestions, err := s.pgdal.CountUserGotdSuggestions(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if openSuggestions >= s.gotdMaxOpenSuggestions {
+		return nil, perr(fmt.Sprintf("you already have %d open suggestions", openSuggestions), http.StatusTooManyRequests)
+	}
+
+	game, err := s.pgdal.GetGame(dbs, suggestion.Game.ID, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if game == nil {
+		return nil, perr("game not found", http.StatusNotFound)
+	}
+	if game.Removed {
+		return nil, perr("game has been removed from Flashpoint", http.StatusBadRequest)
+	}
+	suggestion.Game = game
+
+	history, err := s.pgdal.GetGotdHistoryForGame(dbs, game.ID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	for _, entry := range history {
+		if !entry.AssignedDate.Before(today) {
+			return nil, perr(fmt.Sprintf("game is already scheduled for %s", entry.AssignedDate.Format(constants.GotdDateFormat)), http.StatusConflict)
+		}
+	}
+
+	exists, err := s.pgdal.GotdSuggestionExists(dbs, game.ID, suggestion.SuggestedDate)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if exists {
+		return nil, perr("game has already been suggested for this date", http.StatusConflict)
+	}
+
+	author, err := s.pgdal.GetUser(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	suggestion.Author = author
+
+	err = s.pgdal.SaveGotdSuggestion(dbs, uid, suggestion)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return suggestion.ToExternal(), nil
+}
+
+// DeleteGotdSuggestion withdraws a suggestion, only its author or staff may do so
+func (s *Service) DeleteGotdSuggestion(ctx context.Context, uid string, sugId int64, fpfss types.IFpfss) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	suggestion, err := s.pgdal.GetGotdSuggestion(dbs, sugId, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if suggestion == nil {
+		return perr("suggestion not found", http.StatusNotFound)
+	}
+
+	if suggestion.Author.UserID != uid {
+		user, err := s.pgdal.GetUser(dbs, uid)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return dberr(err)
+		}
+		if !constants.IsStaff(user.Roles) {
+			return perr("user is not the author of this suggestion", http.StatusForbidden)
+		}
+	}
+
+	err = s.pgdal.DeleteGotdSuggestion(dbs, uid, sugId)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// GetGotdFile builds the launcher's gotd.json from every game up to today, along with when it last changed
+func (s *Service) GetGotdFile(ctx context.Context) (*types.GotdFile, time.Time, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, time.Time{}, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	games, err := s.pgdal.GetGotdCurrent(dbs, &types.GetGotdCurrentQuery{ShowFuture: false})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, time.Time{}, dberr(err)
+	}
+
+	// Covers assignments, overwrites and unassignments alike
+	lastModified, err := s.pgdal.GetGotdScheduleUpdatedAt(dbs)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, time.Time{}, dberr(err)
+	}
+
+	file := &types.GotdFile{
+		Games: make([]types.GotdFileGame, len(games)),
+	}
+	for i, game := range games {
+		file.Games[i] = types.GotdFileGame{
+			ID:          game.ID,
+			Author:      game.Author,
+			Description: game.Description,
+			Date:        game.AssignedDate.Format(constants.GotdDateFormat),
+		}
+		// An entry becomes visible on its assigned date, which may be later than the schedule last changed
+		if game.AssignedDate.After(lastModified) {
+			lastModified = game.AssignedDate
+		}
+	}
+
+	return file, lastModified, nil
+}
+
+// ImportGotdFile upserts every entry of a launcher gotd.json into the schedule, matching on date.
+// Entries with unknown games, bad dates or a different game already on that date are reported and skipped.
+func (s *Service) ImportGotdFile(ctx context.Context, uid string, file *types.GotdFile, overwrite bool, fpfss types.IFpfss) (*types.GotdImportResult, error) {
+	result := &types.GotdImportResult{
+		Conflicts:    make([]*types.GotdImportIssue, 0),
+		UnknownGames: make([]*types.GotdImportIssue, 0),
+		Invalid:      make([]*types.GotdImportIssue, 0),
+	}
+
+	// Check the entries themselves before touching FPFSS or the database
+	games := make([]*types.GotdGame, 0)
+	gameIDs := make([]string, 0)
+	seenDates := make(map[string]bool)
+	for _, entry := range file.Games {
+		issue := &types.GotdImportIssue{GameID: entry.ID, Date: entry.Date}
+		date, err := time.Parse(constants.GotdDateFormat, entry.Date)
+		if err != nil {
+			issue.Reason = "date must be in YYYY-MM-DD format"
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		if entry.ID == "" {
+			issue.Reason = "missing game id"
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		if seenDates[entry.Date] {
+			issue.Reason = "date appears more than once in the file"
+			result.Conflicts = append(result.Conflicts, issue)
+			continue
+		}
+		seenDates[entry.Date] = true
+		games = append(games, &types.GotdGame{
+			ID:           entry.ID,
+			Author:       entry.Author,
+			Description:  entry.Description,
+			AssignedDate: date,
+		})
+		gameIDs = append(gameIDs, entry.ID)
+	}
+
+	knownGames, err := findFpfssGames(ctx, gameIDs, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	for _, game := range games {
+		date := game.AssignedDate.Format(constants.GotdDateFormat)
+		if knownGames[strings.ToLower(game.ID)] == nil {
+			result.UnknownGames = append(result.UnknownGames, &types.GotdImportIssue{
+				GameID: game.ID,
+				Date:   date,
+				Reason: "game not found on FPFSS",
+			})
+			continue
+		}
+
+		existing, err := s.pgdal.GetGotdByDate(dbs, date)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+		if existing != nil && !strings.EqualFold(existing.ID, game.ID) && !overwrite {
+			result.Conflicts = append(result.Conflicts, &types.GotdImportIssue{
+				GameID: game.ID,
+				Date:   date,
+				Reason: fmt.Sprintf("date is already assigned to %s", existing.ID),
+			})
+			continue
+		}
+
+		err = s.pgdal.SaveGotd(dbs, uid, game)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+		if existing != nil {
+			result.Updated++
+		} else {
+			result.Imported++
+		}
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+const (
+	defaultGotdScheduleDays = 30
+	maxGotdScheduleDays     = 365
+	defaultGotdDeveloperGap = 14
+)
+
+// PreviewGotdSchedule proposes suggestions for every empty date in the window without saving anything
+func (s *Service) PreviewGotdSchedule(ctx context.Context, query *types.GotdAutoScheduleQuery, fpfss types.IFpfss) (*types.GotdAutoScheduleResponse, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	plan, err := s.planGotdSchedule(dbs, query, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// ApplyGotdSchedule assigns the plan previously returned by PreviewGotdSchedule.
+// The plan is rebuilt and must still match the previewed plan_hash, otherwise nothing is assigned.
+func (s *Service) ApplyGotdSchedule(ctx context.Context, uid string, query *types.GotdAutoScheduleQuery, fpfss types.IFpfss) (*types.GotdAutoScheduleResponse, error) {
+	if query.PlanHash == "" {
+		return nil, perr("plan_hash from the preview is required", http.StatusBadRequest)
+	}
+
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	plan, err := s.planGotdSchedule(dbs, query, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+	if plan.PlanHash != query.PlanHash {
+		return nil, perr("the schedule or suggestions have changed since the preview, preview again", http.StatusConflict)
+	}
+
+	for _, proposal := range plan.Proposals {
+		err = s.pgdal.AssignGotd(dbs, uid, proposal.Suggestion.ID, proposal.Date, fpfss)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return nil, perr("the schedule or suggestions have changed since the preview, preview again", http.StatusConflict)
+			}
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+		err = s.pgdal.DeleteGotdSuggestion(dbs, uid, proposal.Suggestion.ID)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return plan, nil
+}
+
+// planGotdSchedule walks each empty date in the window and picks the first fitting suggestion.
+// Suggestions asking for that exact date win, otherwise the oldest suggestion without a date is used.
+// Extreme and removed games are skipped, as is any game whose developer is featured within the developer gap.
+func (s *Service) planGotdSchedule(dbs database.PGDBSession, query *types.GotdAutoScheduleQuery, fpfss types.IFpfss) (*types.GotdAutoScheduleResponse, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, 1)
+	if query.StartDate != "" {
+		date, err := time.Parse(constants.GotdDateFormat, query.StartDate)
+		if err != nil {
+			return nil, perr("start_date must be in YYYY-MM-DD format", http.StatusBadRequest)
+		}
+		// Past dates have already been shown, filling them would rewrite history
+		if date.Before(today) {
+			return nil, perr("start_date must not be in the past", http.StatusBadRequest)
+		}
+		start = date
+	}
+	days := query.Days
+	if days <= 0 {
+		days = defaultGotdScheduleDays
+	}
+	if days > maxGotdScheduleDays {
+		return nil, perr("days must not exceed 365", http.StatusBadRequest)
+	}
+	gap := query.DeveloperGapDays
+	if gap == 0 {
+		gap = defaultGotdDeveloperGap
+	}
+	if gap < 0 {
+		gap = 0
+	}
+	end := start.AddDate(0, 0, int(days))
+
+	// Find what's already scheduled around the window, including the developer gap either side
+	scheduled, err := s.pgdal.GetGotdCurrent(dbs, &types.GetGotdCurrentQuery{ShowFuture: true})
+	if err != nil {
+		return nil, dberr(err)
+	}
+	occupied := make(map[string]bool)
+	scheduledIDs := make([]string, 0)
+	scheduledDates := make([]time.Time, 0)
+	for _, game := range scheduled {
+		occupied[game.AssignedDate.Format(constants.GotdDateFormat)] = true
+		if !game.AssignedDate.Before(start.AddDate(0, 0, -int(gap))) && game.AssignedDate.Before(end.AddDate(0, 0, int(gap))) {
+			scheduledIDs = append(scheduledIDs, game.ID)
+			scheduledDates = append(scheduledDates, game.AssignedDate)
+		}
+	}
+	scheduledGames, err := s.pgdal.GetGames(dbs, utils.RemoveSliceDuplicates(scheduledIDs), fpfss)
+	if err != nil {
+		return nil, dberr(err)
+	}
+	developers := make(map[string]string)
+	for _, game := range scheduledGames {
+		developers[game.ID] = game.Developer
+	}
+	featured := make([]featuredDeveloper, len(scheduledIDs))
+	for i, id := range scheduledIDs {
+		featured[i] = featuredDeveloper{
+			Developer: strings.ToLower(strings.TrimSpace(developers[id])),
+			Date:      scheduledDates[i],
+		}
+	}
+
+	suggestions, err := s.pgdal.GetPendingGotdSuggestions(dbs, fpfss)
+	if err != nil {
+		return nil, dberr(err)
+	}
+
+	plan := &types.GotdAutoScheduleResponse{
+		Proposals:     make([]*types.GotdProposal, 0),
+		UnfilledDates: make([]string, 0),
+	}
+	used := make(map[int64]bool)
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		dateStr := date.Format(constants.GotdDateFormat)
+		if occupied[dateStr] {
+			continue
+		}
+
+		var pick *types.GotdSuggestionInternal
+		for _, wantsDate := range []bool{true, false} {
+			for _, suggestion := range suggestions {
+				if used[suggestion.ID] || suggestion.Game == nil || suggestion.Game.Missing || suggestion.Game.Removed || suggestion.Game.Extreme {
+					continue
+				}
+				if wantsDate != isSuggestedFor(suggestion, date) {
+					continue
+				}
+				if !wantsDate && suggestion.SuggestedDate != nil && !suggestion.SuggestedDate.Before(start) {
+					// Reserved for another date in the future
+					continue
+				}
+				if developerFeaturedNear(featured, suggestion.Game.Developer, date, gap) {
+					continue
+				}
+				pick = suggestion
+				break
+			}
+			if pick != nil {
+				break
+			}
+		}
+
+		if pick == nil {
+			plan.UnfilledDates = append(plan.UnfilledDates, dateStr)
+			continue
+		}
+		used[pick.ID] = true
+		featured = append(featured, featuredDeveloper{
+			Developer: strings.ToLower(strings.TrimSpace(pick.Game.Developer)),
+			Date:      date,
+		})
+		plan.Proposals = append(plan.Proposals, &types.GotdProposal{
+			Date:       dateStr,
+			Suggestion: pick.ToExternal(),
+		})
+	}
+	plan.PlanHash = hashGotdPlan(plan)
+
+	return plan, nil
+}
+
+// hashGotdPlan fingerprints the proposed assignments and unfilled dates so a preview can be checked before applying
+func hashGotdPlan(plan *types.GotdAutoScheduleResponse) string {
+	h := sha256.New()
+	for _, proposal := range plan.Proposals {
+		fmt.Fprintf(h, "%s:%d:%s:%q\n", proposal.Date, proposal.Suggestion.ID, proposal.Suggestion.Game.ID, proposal.Suggestion.Description)
+	}
+	for _, date := range plan.UnfilledDates {
+		fmt.Fprintf(h, "%s\n", date)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type featuredDeveloper struct {
+	Developer string
+	Date      time.Time
+}
+
+// isSuggestedFor reports whether the suggestion asked for this exact date
+func isSuggestedFor(suggestion *types.GotdSuggestionInternal, date time.Time) bool {
+	if suggestion.SuggestedDate == nil {
+		return false
+	}
+	return suggestion.SuggestedDate.Format(constants.GotdDateFormat) == date.Format(constants.GotdDateFormat)
+}
+
+// developerFeaturedNear reports whether the developer already has a game within gap days of the date
+func developerFeaturedNear(featured []featuredDeveloper, developer string, date time.Time, gap int64) bool {
+	developer = strings.ToLower(strings.TrimSpace(developer))
+	if developer == "" || gap == 0 {
+		return false
+	}
+	for _, f := range featured {
+		if f.Developer != developer {
+			continue
+		}
+		diff := f.Date.Sub(date)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < time.Duration(gap)*24*time.Hour {
+			return true
+		}
+	}
+	return false
+}
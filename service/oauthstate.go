@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// oauthStateTTL is how long a user has to complete a login at the OAuth provider
+const oauthStateTTL = 10 * time.Minute
+
+// CreateOAuthState stores a pending login with a fresh nonce and PKCE code verifier
+func (s *Service) CreateOAuthState(ctx context.Context, redirectURI string) (*types.OAuthState, error) {
+	nonce, err := randomSecret()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+	verifier, err := randomSecret()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+	state := &types.OAuthState{
+		Nonce:        nonce,
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	if err = s.pgdal.SaveOAuthState(dbs, state, oauthStateTTL); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return state, nil
+}
+
+// ConsumeOAuthState returns the pending login for the nonce and removes it, returning nil if it is unknown,
+// expired or already used
+func (s *Service) ConsumeOAuthState(ctx context.Context, nonce string) (*types.OAuthState, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	state, err := s.pgdal.ConsumeOAuthState(dbs, nonce)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return state, nil
+}
+
+// PurgeExpiredOAuthStates deletes logins that were never completed, returning how many were deleted
+func (s *Service) PurgeExpiredOAuthStates(ctx context.Context) (int64, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return 0, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	purged, err := s.pgdal.PurgeExpiredOAuthStates(dbs)
+	if err != nil {
+		return 0, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		return 0, dberr(err)
+	}
+
+	return purged, nil
+}
@@ -3,32 +3,49 @@ package service
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"net/http"
+	"strings"
 
-	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/database"
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
 )
 
-func (s *Service) SearchPlaylists(ctx context.Context, searchOpts *types.PlaylistSearchQuery) ([]*types.Playlist, int64, error) {
+// SearchPlaylists returns a page of matching playlists, plus facet counts over every match when the query asks for them
+func (s *Service) SearchPlaylists(ctx context.Context, uid string, searchOpts *types.PlaylistSearchQuery) ([]*types.Playlist, int64, *types.PlaylistSearchFacets, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
-		return nil, 0, dberr(err)
+		return nil, 0, nil, dberr(err)
 	}
 	defer dbs.Rollback()
 
+	searchOpts.ViewerID = uid
+	searchOpts.Filter, err = s.contentFilter(dbs, uid, searchOpts.Extreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, 0, nil, dberr(err)
+	}
+
 	playlists, total, err := s.pgdal.SearchPlaylists(dbs, searchOpts)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
-		return nil, 0, dberr(err)
+		return nil, 0, nil, dberr(err)
 	}
 
-	return playlists, total, nil
+	var facets *types.PlaylistSearchFacets
+	if searchOpts.IncludeFacets {
+		facets, err = s.pgdal.GetPlaylistSearchFacets(dbs, searchOpts)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, 0, nil, dberr(err)
+		}
+	}
+
+	return playlists, total, facets, nil
 }
 
-func (s *Service) GetDownloadablePlaylist(ctx context.Context, id int64) (*types.Playlist, error) {
+func (s *Service) GetDownloadablePlaylist(ctx context.Context, uid string, id int64) (*types.Playlist, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -44,11 +61,25 @@ func (s *Service) GetDownloadablePlaylist(ctx context.Context, id int64) (*types
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
 	}
+	if playlist == nil {
+		return nil, nil
+	}
+
+	visible, err := s.canViewPlaylist(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if !visible {
+		return nil, nil
+	}
 
 	return playlist, nil
 }
 
-func (s *Service) GetPlaylist(ctx context.Context, id int64, fpfss types.IFpfss) (*types.FullPlaylist, error) {
+// GetExportablePlaylist returns the playlist with every game's cached metadata, leaving out any the viewer's content
+// filter hides
+func (s *Service) GetExportablePlaylist(ctx context.Context, uid string, includeExtreme bool, id int64, fpfss types.IFpfss) (*types.FullPlaylist, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -66,12 +97,103 @@ func (s *Service) GetPlaylist(ctx context.Context, id int64, fpfss types.IFpfss)
 		return nil, nil
 	}
 
+	visible, err := s.canViewPlaylist(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if !visible {
+		return nil, nil
+	}
+
 	populatedPlaylist, err := s.fillPlaylist(dbs, playlist, fpfss)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
 	}
 
+	filter, err := s.contentFilter(dbs, uid, includeExtreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	hideFilteredGames(populatedPlaylist, filter)
+
+	return populatedPlaylist, nil
+}
+
+// hideFilteredGames drops the games the filter hides from the playlist, counting them in HiddenGames
+func hideFilteredGames(playlist *types.FullPlaylist, filter *types.ContentFilter) {
+	visibleGames := make([]types.GameWithNotes, 0, len(playlist.Games))
+	for _, game := range playlist.Games {
+		if filter.Hides(game.Game) {
+			playlist.HiddenGames++
+			continue
+		}
+		visibleGames = append(visibleGames, game)
+	}
+	playlist.Games = visibleGames
+}
+
+// GetPlaylist returns the playlist with its games, leaving out any the viewer's content filter hides
+func (s *Service) GetPlaylist(ctx context.Context, uid string, includeExtreme bool, id int64, fpfss types.IFpfss) (*types.FullPlaylist, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.pgdal.GetPlaylist(dbs, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	if playlist == nil {
+		return nil, nil
+	}
+
+	visible, err := s.canViewPlaylist(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if !visible {
+		return nil, nil
+	}
+
+	populatedPlaylist, err := s.fillPlaylist(dbs, playlist, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	filter, err := s.contentFilter(dbs, uid, includeExtreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	hideFilteredGames(populatedPlaylist, filter)
+
+	populatedPlaylist.Lineage, err = s.getPlaylistLineage(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	if uid != "" {
+		reactions, err := s.pgdal.GetPlaylistReactions(dbs, id, uid)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+		if reactions != nil {
+			populatedPlaylist.Liked = reactions.Liked
+			populatedPlaylist.Bookmarked = reactions.Bookmarked
+		}
+	}
+
 	return populatedPlaylist, nil
 }
 
@@ -88,6 +210,11 @@ func (s *Service) SubmitPlaylist(ctx context.Context, uid string, playlist *type
 		utils.LogCtx(ctx).Error(err)
 		return dberr(err)
 	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
 
 	err = dbs.Commit()
 	if err != nil {
@@ -110,6 +237,9 @@ func (s *Service) fillPlaylist(dbs database.PGDBSession, playlist *types.Playlis
 		Public:       playlist.Public,
 		Extreme:      playlist.Extreme,
 		FilterGroups: playlist.FilterGroups,
+		ForkedFrom:   playlist.ForkedFrom,
+		ForkCount:    playlist.ForkCount,
+		Likes:        playlist.Likes,
 		CreatedAt:    playlist.CreatedAt,
 		UpdatedAt:    playlist.UpdatedAt,
 		TotalGames:   playlist.TotalGames,
@@ -144,27 +274,35 @@ func (s *Service) UpdatePlaylist(ctx context.Context, uid string, playlist *type
 	}
 	defer dbs.Rollback()
 
-	existingPlaylist, err := s.pgdal.GetPlaylist(dbs, playlist.ID)
+	existingPlaylist, err := s.requirePlaylistAccess(dbs, uid, playlist.ID, playlistAccessEdit, false)
 	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		return nil, dberr(err)
-	}
-	if existingPlaylist == nil {
-		return nil, fmt.Errorf("playlist not found")
-	}
-
-	if existingPlaylist.Author.UserID != uid {
-		return nil, fmt.Errorf("user is not the author of this playlist")
+		return nil, err
 	}
 
 	existingPlaylist.Games = playlist.Games
+	existingPlaylist.TotalGames = len(playlist.Games)
 	existingPlaylist.Name = playlist.Name
 	existingPlaylist.Description = playlist.Description
 	existingPlaylist.Library = playlist.Library
 	existingPlaylist.Icon = playlist.Icon
-	existingPlaylist.Public = playlist.Public
 
-	err = s.pgdal.SavePlaylist(dbs, uid, playlist, fpfss)
+	// Editors can't change who can see the playlist, only the owner and staff can
+	access, staff, err := s.getPlaylistAccess(dbs, uid, existingPlaylist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if access == playlistAccessOwner || staff {
+		existingPlaylist.Public = playlist.Public
+	}
+
+	// Editors save on the author's behalf, the revision records who made the change
+	err = s.pgdal.SavePlaylist(dbs, existingPlaylist.Author.UserID, existingPlaylist, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, existingPlaylist)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return nil, dberr(err)
@@ -187,24 +325,9 @@ func (s *Service) DeletePlaylist(ctx context.Context, uid string, id int64) erro
 	}
 	defer dbs.Rollback()
 
-	playlist, err := s.pgdal.GetPlaylist(dbs, id)
+	_, err = s.requirePlaylistAccess(dbs, uid, id, playlistAccessOwner, true)
 	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		return dberr(err)
-	}
-	if playlist == nil {
-		return fmt.Errorf("playlist not found")
-	}
-
-	roles, err := s.pgdal.GetRoles(dbs)
-	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		return dberr(err)
-	}
-	if !constants.IsModerator(roles) {
-		if playlist.Author.UserID != uid {
-			return fmt.Errorf("user is not the author of this playlist")
-		}
+		return err
 	}
 
 	err = s.pgdal.DeletePlaylist(dbs, id)
@@ -319,7 +442,7 @@ func (s *Service) SubmitContentReport(ctx context.Context, report *types.Content
 	return nil
 }
 
-func (s *Service) GetGame(ctx context.Context, id string, fpfss types.IFpfss) (*types.CachedGame, error) {
+func (s *Service) GetGame(ctx context.Context, uid string, includeExtreme bool, id string, fpfss types.IFpfss) (*types.CachedGame, error) {
 	dbs, err := s.pgdal.NewSession(ctx)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
@@ -333,5 +456,131 @@ func (s *Service) GetGame(ctx context.Context, id string, fpfss types.IFpfss) (*
 		return nil, dberr(err)
 	}
 
+	filter, err := s.contentFilter(dbs, uid, includeExtreme)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if filter.Hides(game) {
+		return nil, perr("this game is hidden by your content filters", http.StatusForbidden)
+	}
+
 	return game, nil
 }
+
+// ImportPlaylist creates a private draft from a launcher playlist file, keeping only the games FPFSS knows about
+func (s *Service) ImportPlaylist(ctx context.Context, uid string, file *types.LauncherPlaylist, fpfss types.IFpfss) (*types.PlaylistImportResult, error) {
+	result := &types.PlaylistImportResult{
+		UnknownGames: make([]*types.PlaylistImportIssue, 0),
+		Invalid:      make([]*types.PlaylistImportIssue, 0),
+		Duplicates:   make([]*types.PlaylistImportIssue, 0),
+	}
+
+	// Check the entries themselves before touching FPFSS or the database
+	entries := make([]int, 0)
+	gameIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for i, game := range file.Games {
+		issue := &types.PlaylistImportIssue{Index: i, GameID: game.GameID}
+		id := strings.ToLower(strings.TrimSpace(game.GameID))
+		if id == "" {
+			issue.Reason = "missing game id"
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		if len(id) != 36 {
+			issue.Reason = "invalid game id"
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		if seen[id] {
+			issue.Reason = "game appears more than once in the playlist"
+			result.Duplicates = append(result.Duplicates, issue)
+			continue
+		}
+		seen[id] = true
+		entries = append(entries, i)
+		gameIDs = append(gameIDs, id)
+	}
+
+	knownGames, err := findFpfssGames(ctx, gameIDs, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, err
+	}
+
+	playlist := &types.Playlist{
+		Name:        strings.TrimSpace(file.Title),
+		Description: file.Description,
+		Library:     file.Library,
+		Icon:        file.Icon,
+		Games:       make([]types.LauncherPlaylistGame, 0),
+		Public:      false,
+	}
+	if playlist.Library == "" {
+		playlist.Library = "arcade"
+	}
+	fpfssGames := make([]*types.FpfssGame, 0)
+	for _, i := range entries {
+		entry := file.Games[i]
+		game := knownGames[strings.ToLower(strings.TrimSpace(entry.GameID))]
+		if game == nil {
+			result.UnknownGames = append(result.UnknownGames, &types.PlaylistImportIssue{
+				Index:  i,
+				GameID: entry.GameID,
+				Reason: "game not found on FPFSS",
+			})
+			continue
+		}
+		fpfssGames = append(fpfssGames, game)
+		playlist.Games = append(playlist.Games, types.LauncherPlaylistGame{
+			GameID: game.ID,
+			Notes:  entry.Notes,
+		})
+	}
+	if len(playlist.Games) == 0 {
+		return nil, perr("none of the games in the playlist could be found", http.StatusBadRequest)
+	}
+	playlist.TotalGames = len(playlist.Games)
+
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	// Already fetched, so cache them rather than have SavePlaylist ask FPFSS again
+	err = s.pgdal.CacheGames(dbs, fpfssGames)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = s.pgdal.SavePlaylist(dbs, uid, playlist, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	saved, err := s.pgdal.GetPlaylist(dbs, playlist.ID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	result.Playlist = saved
+	result.Imported = len(playlist.Games)
+	return result, nil
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+// playlistAccess is what a user may do with a playlist, each level including the ones below it
+type playlistAccess int
+
+const (
+	playlistAccessNone playlistAccess = iota
+	playlistAccessView
+	playlistAccessEdit
+	playlistAccessOwner
+)
+
+// getPlaylistAccess works out the user's access from authorship and accepted collaborator roles, and whether they are staff.
+// Public playlists can be viewed by anyone.
+func (s *Service) getPlaylistAccess(dbs database.PGDBSession, uid string, playlist *types.Playlist) (playlistAccess, bool, error) {
+	access := playlistAccessNone
+	if playlist.Public {
+		access = playlistAccessView
+	}
+	if uid == "" {
+		return access, false, nil
+	}
+	if playlist.Author.UserID == uid {
+		return playlistAccessOwner, false, nil
+	}
+
+	collaborator, err := s.pgdal.GetPlaylistCollaborator(dbs, playlist.ID, uid)
+	if err != nil {
+		return access, false, err
+	}
+	if collaborator != nil && collaborator.AcceptedAt != nil {
+		switch collaborator.Role {
+		case types.PlaylistRoleEditor:
+			access = playlistAccessEdit
+		case types.PlaylistRoleViewer:
+			access = playlistAccessView
+		}
+	}
+
+	user, err := s.pgdal.GetUser(dbs, uid)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return access, false, nil
+		}
+		return access, false, err
+	}
+	return access, constants.IsStaff(user.Roles), nil
+}
+
+// requirePlaylistAccess loads the playlist and checks the user has at least the given access, or is staff when allowStaff is set.
+// Playlists the user can't see at all are reported as not found.
+func (s *Service) requirePlaylistAccess(dbs database.PGDBSession, uid string, playlistID int64, required playlistAccess, allowStaff bool) (*types.Playlist, error) {
+	playlist, err := s.pgdal.GetPlaylist(dbs, playlistID)
+	if err != nil {
+		utils.LogCtx(dbs.Ctx()).Error(err)
+		return nil, dberr(err)
+	}
+	if playlist == nil {
+		return nil, perr("playlist not found", http.StatusNotFound)
+	}
+
+	access, staff, err := s.getPlaylistAccess(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(dbs.Ctx()).Error(err)
+		return nil, dberr(err)
+	}
+	if access >= required || (allowStaff && staff) {
+		return playlist, nil
+	}
+	if access == playlistAccessNone && !staff {
+		return nil, perr("playlist not found", http.StatusNotFound)
+	}
+	return nil, perr("you do not have permission to do that to this playlist", http.StatusForbidden)
+}
+
+// GetPlaylistCollaborators lists the playlist's collaborators. Pending invites are only shown to the owner and staff,
+// who are the ones able to manage them.
+func (s *Service) GetPlaylistCollaborators(ctx context.Context, uid string, playlistID int64) ([]*types.PlaylistCollaborator, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessView, true)
+	if err != nil {
+		return nil, err
+	}
+	access, staff, err := s.getPlaylistAccess(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	collaborators, err := s.pgdal.GetPlaylistCollaborators(dbs, playlistID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if access == playlistAccessOwner || staff {
+		return collaborators, nil
+	}
+
+	accepted := make([]*types.PlaylistCollaborator, 0, len(collaborators))
+	for _, collaborator := range collaborators {
+		if collaborator.AcceptedAt != nil {
+			accepted = append(accepted, collaborator)
+		}
+	}
+	return accepted, nil
+}
+
+// InvitePlaylistCollaborator invites a user with the given role, or changes the role of an existing collaborator
+func (s *Service) InvitePlaylistCollaborator(ctx context.Context, uid string, playlistID int64, sub *types.SubmittedPlaylistCollaborator) (*types.PlaylistCollaborator, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessOwner, true)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserID == playlist.Author.UserID {
+		return nil, perr("the author can't be a collaborator on their own playlist", http.StatusBadRequest)
+	}
+
+	user, err := s.pgdal.GetUser(dbs, sub.UserID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, perr("user not found", http.StatusNotFound)
+		}
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = s.pgdal.SavePlaylistCollaborator(dbs, &types.PlaylistCollaborator{
+		PlaylistID: playlistID,
+		User:       user,
+		Role:       sub.Role,
+		InvitedBy:  uid,
+	})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	collaborator, err := s.pgdal.GetPlaylistCollaborator(dbs, playlistID, sub.UserID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("user %s set %s as %s on playlist %d", uid, sub.UserID, sub.Role, playlistID)
+	return collaborator, nil
+}
+
+func (s *Service) AcceptPlaylistInvite(ctx context.Context, uid string, playlistID int64) (*types.PlaylistCollaborator, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	collaborator, err := s.pgdal.GetPlaylistCollaborator(dbs, playlistID, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if collaborator == nil {
+		return nil, perr("invite not found", http.StatusNotFound)
+	}
+
+	err = s.pgdal.AcceptPlaylistCollaborator(dbs, playlistID, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	collaborator, err = s.pgdal.GetPlaylistCollaborator(dbs, playlistID, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return collaborator, nil
+}
+
+// RemovePlaylistCollaborator removes a collaborator or pending invite. Collaborators may remove themselves.
+func (s *Service) RemovePlaylistCollaborator(ctx context.Context, uid string, playlistID int64, collaboratorID string) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	// Authorize before looking the collaborator up, so the response doesn't reveal who collaborates
+	if collaboratorID != uid {
+		_, err = s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessOwner, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	collaborator, err := s.pgdal.GetPlaylistCollaborator(dbs, playlistID, collaboratorID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if collaborator == nil {
+		return perr("collaborator not found", http.StatusNotFound)
+	}
+
+	err = s.pgdal.DeletePlaylistCollaborator(dbs, playlistID, collaboratorID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("user %s removed %s from playlist %d", uid, collaboratorID, playlistID)
+	return nil
+}
+
+func (s *Service) GetPlaylistInvites(ctx context.Context, uid string) ([]*types.PlaylistCollaborator, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	invites, err := s.pgdal.GetUserPlaylistInvites(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return invites, nil
+}
+
+// canViewPlaylist reports whether the user may see the playlist at all. Private playlists are only visible to their
+// author, accepted collaborators and staff.
+func (s *Service) canViewPlaylist(dbs database.PGDBSession, uid string, playlist *types.Playlist) (bool, error) {
+	access, staff, err := s.getPlaylistAccess(dbs, uid, playlist)
+	if err != nil {
+		return false, err
+	}
+	return access >= playlistAccessView || staff, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// playlistTopForksLimit is how many forks are shown in a playlist's lineage
+const playlistTopForksLimit = 5
+
+// ForkPlaylist copies a public playlist, games and notes included, into a new private playlist owned by the user
+func (s *Service) ForkPlaylist(ctx context.Context, uid string, id int64, fpfss types.IFpfss) (*types.Playlist, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	source, err := s.requirePlaylistAccess(dbs, uid, id, playlistAccessView, false)
+	if err != nil {
+		return nil, err
+	}
+	if !source.Public && source.Author.UserID != uid {
+		return nil, perr("only public playlists can be forked", http.StatusForbidden)
+	}
+
+	games := make([]types.LauncherPlaylistGame, len(source.Games))
+	copy(games, source.Games)
+	fork := &types.Playlist{
+		Name:        source.Name,
+		Description: source.Description,
+		Library:     source.Library,
+		Icon:        source.Icon,
+		Games:       games,
+		TotalGames:  len(games),
+		Public:      false,
+		ForkedFrom:  &source.ID,
+	}
+
+	err = s.pgdal.SavePlaylist(dbs, uid, fork, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, fork)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	saved, err := s.pgdal.GetPlaylist(dbs, fork.ID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("user %s forked playlist %d into %d", uid, source.ID, saved.ID)
+	return saved, nil
+}
+
+// getPlaylistLineage finds the playlist's source, if the user can see it, and its most liked public forks, ties going to the most forked
+func (s *Service) getPlaylistLineage(dbs database.PGDBSession, uid string, playlist *types.Playlist) (*types.PlaylistLineage, error) {
+	lineage := &types.PlaylistLineage{}
+
+	if playlist.ForkedFrom != nil {
+		source, err := s.pgdal.GetPlaylist(dbs, *playlist.ForkedFrom)
+		if err != nil {
+			return nil, err
+		}
+		if source != nil {
+			visible, err := s.canViewPlaylist(dbs, uid, source)
+			if err != nil {
+				return nil, err
+			}
+			if visible {
+				lineage.Source = toPlaylistInfo(source)
+			}
+		}
+	}
+
+	forks, err := s.pgdal.GetPopularPlaylistForks(dbs, playlist.ID, playlistTopForksLimit)
+	if err != nil {
+		return nil, err
+	}
+	lineage.TopForks = forks
+
+	return lineage, nil
+}
+
+func toPlaylistInfo(playlist *types.Playlist) *types.PlaylistInfo {
+	return &types.PlaylistInfo{
+		ID:           playlist.ID,
+		Name:         playlist.Name,
+		TotalGames:   playlist.TotalGames,
+		Description:  playlist.Description,
+		Author:       playlist.Author,
+		Library:      playlist.Library,
+		Icon:         playlist.Icon,
+		Public:       playlist.Public,
+		Extreme:      playlist.Extreme,
+		FilterGroups: playlist.FilterGroups,
+		ForkedFrom:   playlist.ForkedFrom,
+		ForkCount:    playlist.ForkCount,
+		Likes:        playlist.Likes,
+		CreatedAt:    playlist.CreatedAt,
+		UpdatedAt:    playlist.UpdatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// PatchPlaylistGames applies the operations in order to the playlist's games and saves the result as one revision
+func (s *Service) PatchPlaylistGames(ctx context.Context, uid string, playlistID int64, ops []*types.PlaylistGameOperation, fpfss types.IFpfss) (*types.Playlist, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessEdit, false)
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := applyPlaylistGameOperations(playlist.Games, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only games new to the playlist need checking
+	existing := make(map[string]bool)
+	for _, game := range playlist.Games {
+		existing[strings.ToLower(game.GameID)] = true
+	}
+	for _, game := range games {
+		if existing[strings.ToLower(game.GameID)] {
+			continue
+		}
+		cached, err := s.pgdal.GetGame(dbs, game.GameID, fpfss)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			return nil, dberr(err)
+		}
+		if cached == nil {
+			return nil, perr(fmt.Sprintf("game %s not found", game.GameID), http.StatusNotFound)
+		}
+		if cached.Removed {
+			return nil, perr(fmt.Sprintf("game %s has been removed from Flashpoint", game.GameID), http.StatusBadRequest)
+		}
+	}
+
+	playlist.Games = games
+	playlist.TotalGames = len(games)
+	err = s.pgdal.SavePlaylist(dbs, playlist.Author.UserID, playlist, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	saved, err := s.pgdal.GetPlaylist(dbs, playlistID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return saved, nil
+}
+
+func applyPlaylistGameOperations(games []types.LauncherPlaylistGame, ops []*types.PlaylistGameOperation) ([]types.LauncherPlaylistGame, error) {
+	res := make([]types.LauncherPlaylistGame, len(games))
+	copy(res, games)
+
+	indexOf := func(id string) int {
+		for i, game := range res {
+			if strings.EqualFold(game.GameID, id) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for n, op := range ops {
+		fail := func(msg string, status int) ([]types.LauncherPlaylistGame, error) {
+			return nil, perr(fmt.Sprintf("operation %d (%s): %s", n, op.Op, msg), status)
+		}
+		switch op.Op {
+		case types.PlaylistGameOpInsert:
+			if len(op.GameID) != 36 {
+				return fail("invalid game id", http.StatusBadRequest)
+			}
+			if indexOf(op.GameID) != -1 {
+				return fail("game is already in the playlist", http.StatusConflict)
+			}
+			index := len(res)
+			if op.Index != nil {
+				index = *op.Index
+			}
+			if index < 0 || index > len(res) {
+				return fail("index out of range", http.StatusBadRequest)
+			}
+			res = append(res, types.LauncherPlaylistGame{})
+			copy(res[index+1:], res[index:])
+			res[index] = types.LauncherPlaylistGame{GameID: op.GameID, Notes: op.Notes}
+		case types.PlaylistGameOpMove:
+			from := indexOf(op.GameID)
+			if from == -1 {
+				return fail("game is not in the playlist", http.StatusNotFound)
+			}
+			if op.Index == nil || *op.Index < 0 || *op.Index >= len(res) {
+				return fail("index out of range", http.StatusBadRequest)
+			}
+			game := res[from]
+			res = append(res[:from], res[from+1:]...)
+			res = append(res[:*op.Index], append([]types.LauncherPlaylistGame{game}, res[*op.Index:]...)...)
+		case types.PlaylistGameOpRemove:
+			i := indexOf(op.GameID)
+			if i == -1 {
+				return fail("game is not in the playlist", http.StatusNotFound)
+			}
+			res = append(res[:i], res[i+1:]...)
+		case types.PlaylistGameOpReorder:
+			if len(op.GameIDs) != len(res) {
+				return fail("game_ids must list every game in the playlist exactly once", http.StatusBadRequest)
+			}
+			reordered := make([]types.LauncherPlaylistGame, 0, len(res))
+			seen := make(map[int]bool)
+			for _, id := range op.GameIDs {
+				i := indexOf(id)
+				if i == -1 || seen[i] {
+					return fail("game_ids must list every game in the playlist exactly once", http.StatusBadRequest)
+				}
+				seen[i] = true
+				reordered = append(reordered, res[i])
+			}
+			res = reordered
+		default:
+			return fail("unknown operation", http.StatusBadRequest)
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, perr("playlist must contain at least one game", http.StatusBadRequest)
+	}
+	return res, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+)
+
+// testGameID builds a game ID of the length FPFSS uses
+func testGameID(n int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+}
+
+func playlistGames(ns ...int) []types.LauncherPlaylistGame {
+	games := make([]types.LauncherPlaylistGame, len(ns))
+	for i, n := range ns {
+		games[i] = types.LauncherPlaylistGame{GameID: testGameID(n)}
+	}
+	return games
+}
+
+func index(i int) *int {
+	return &i
+}
+
+func TestApplyPlaylistGameOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		games      []types.LauncherPlaylistGame
+		ops        []*types.PlaylistGameOperation
+		want       []int
+		wantStatus int
+	}{
+		{
+			name:  "insert appends without an index",
+			games: playlistGames(1, 2),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: testGameID(3)}},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:  "insert at the start",
+			games: playlistGames(1, 2),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: testGameID(3), Index: index(0)}},
+			want:  []int{3, 1, 2},
+		},
+		{
+			name:  "insert at the length appends",
+			games: playlistGames(1, 2),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: testGameID(3), Index: index(2)}},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:       "insert past the end",
+			games:      playlistGames(1, 2),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: testGameID(3), Index: index(3)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "insert at a negative index",
+			games:      playlistGames(1, 2),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: testGameID(3), Index: index(-1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "insert a malformed id",
+			games:      playlistGames(1),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: "not-a-game"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "insert a game already present in another case",
+			games:      []types.LauncherPlaylistGame{{GameID: "aaaaaaaa-0000-0000-0000-000000000001"}},
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpInsert, GameID: "AAAAAAAA-0000-0000-0000-000000000001"}},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "move to the start",
+			games: playlistGames(1, 2, 3),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(3), Index: index(0)}},
+			want:  []int{3, 1, 2},
+		},
+		{
+			name:  "move to the last position",
+			games: playlistGames(1, 2, 3),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(1), Index: index(2)}},
+			want:  []int{2, 3, 1},
+		},
+		{
+			name:  "move onto its own position",
+			games: playlistGames(1, 2, 3),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(2), Index: index(1)}},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:       "move to the length",
+			games:      playlistGames(1, 2, 3),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(1), Index: index(3)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "move without an index",
+			games:      playlistGames(1, 2),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "move a game not in the playlist",
+			games:      playlistGames(1, 2),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpMove, GameID: testGameID(9), Index: index(0)}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "remove",
+			games: playlistGames(1, 2, 3),
+			ops:   []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpRemove, GameID: testGameID(2)}},
+			want:  []int{1, 3},
+		},
+		{
+			name:       "remove a game not in the playlist",
+			games:      playlistGames(1, 2),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpRemove, GameID: testGameID(9)}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "remove the last game",
+			games:      playlistGames(1),
+			ops:        []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpRemove, GameID: testGameID(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "reorder",
+			games: playlistGames(1, 2, 3),
+			ops: []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpReorder, GameIDs: []string{
+				testGameID(2), testGameID(3), testGameID(1),
+			}}},
+			want: []int{2, 3, 1},
+		},
+		{
+			name:  "reorder listing a game twice",
+			games: playlistGames(1, 2, 3),
+			ops: []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpReorder, GameIDs: []string{
+				testGameID(1), testGameID(1), testGameID(2),
+			}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "reorder missing a game",
+			games: playlistGames(1, 2, 3),
+			ops: []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpReorder, GameIDs: []string{
+				testGameID(1), testGameID(2),
+			}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "reorder with an unknown game",
+			games: playlistGames(1, 2),
+			ops: []*types.PlaylistGameOperation{{Op: types.PlaylistGameOpReorder, GameIDs: []string{
+				testGameID(1), testGameID(9),
+			}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown operation",
+			games:      playlistGames(1),
+			ops:        []*types.PlaylistGameOperation{{Op: "shuffle"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "operations apply in order",
+			games: playlistGames(1, 2, 3),
+			ops: []*types.PlaylistGameOperation{
+				{Op: types.PlaylistGameOpInsert, GameID: testGameID(4), Index: index(1)},
+				{Op: types.PlaylistGameOpRemove, GameID: testGameID(1)},
+				{Op: types.PlaylistGameOpMove, GameID: testGameID(3), Index: index(0)},
+			},
+			want: []int{3, 4, 2},
+		},
+		{
+			name:  "a later failure rejects the whole patch",
+			games: playlistGames(1, 2),
+			ops: []*types.PlaylistGameOperation{
+				{Op: types.PlaylistGameOpRemove, GameID: testGameID(1)},
+				{Op: types.PlaylistGameOpRemove, GameID: testGameID(1)},
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := make([]types.LauncherPlaylistGame, len(tt.games))
+			copy(original, tt.games)
+
+			got, err := applyPlaylistGameOperations(tt.games, tt.ops)
+
+			for i := range original {
+				if tt.games[i] != original[i] {
+					t.Fatalf("input games were modified: %v, was %v", tt.games, original)
+				}
+			}
+			if tt.wantStatus != 0 {
+				pubErr, ok := err.(constants.PublicError)
+				if !ok {
+					t.Fatalf("err = %v, want a public error with status %d", err, tt.wantStatus)
+				}
+				if pubErr.Status != tt.wantStatus {
+					t.Fatalf("status = %d (%s), want %d", pubErr.Status, pubErr.Msg, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := playlistGames(tt.want...)
+			if len(got) != len(want) {
+				t.Fatalf("games = %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i].GameID != want[i].GameID {
+					t.Fatalf("games = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPlaylistGameOperationsKeepsNotes(t *testing.T) {
+	games := []types.LauncherPlaylistGame{{GameID: testGameID(1), Notes: "first"}}
+	ops := []*types.PlaylistGameOperation{
+		{Op: types.PlaylistGameOpInsert, GameID: testGameID(2), Notes: "second", Index: index(0)},
+		{Op: types.PlaylistGameOpMove, GameID: testGameID(1), Index: index(0)},
+	}
+
+	got, err := applyPlaylistGameOperations(games, ops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []types.LauncherPlaylistGame{{GameID: testGameID(1), Notes: "first"}, {GameID: testGameID(2), Notes: "second"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("games = %v, want %v", got, want)
+	}
+}
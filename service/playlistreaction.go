@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/FlashpointProject/CommunityWebsite/database"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// LikePlaylist likes the playlist for the user, authors can't like their own playlists
+func (s *Service) LikePlaylist(ctx context.Context, uid string, id int64) (*types.PlaylistReactions, error) {
+	return s.reactToPlaylist(ctx, uid, id, false, func(dbs database.PGDBSession) error {
+		_, err := s.pgdal.LikePlaylist(dbs, id, uid)
+		return err
+	})
+}
+
+func (s *Service) UnlikePlaylist(ctx context.Context, uid string, id int64) (*types.PlaylistReactions, error) {
+	return s.reactToPlaylist(ctx, uid, id, true, func(dbs database.PGDBSession) error {
+		_, err := s.pgdal.UnlikePlaylist(dbs, id, uid)
+		return err
+	})
+}
+
+func (s *Service) BookmarkPlaylist(ctx context.Context, uid string, id int64) (*types.PlaylistReactions, error) {
+	return s.reactToPlaylist(ctx, uid, id, true, func(dbs database.PGDBSession) error {
+		return s.pgdal.BookmarkPlaylist(dbs, id, uid)
+	})
+}
+
+func (s *Service) UnbookmarkPlaylist(ctx context.Context, uid string, id int64) (*types.PlaylistReactions, error) {
+	return s.reactToPlaylist(ctx, uid, id, true, func(dbs database.PGDBSession) error {
+		return s.pgdal.UnbookmarkPlaylist(dbs, id, uid)
+	})
+}
+
+// reactToPlaylist runs the change against a playlist the user can see and returns the playlist's reactions afterwards
+func (s *Service) reactToPlaylist(ctx context.Context, uid string, id int64, allowAuthor bool, react func(database.PGDBSession) error) (*types.PlaylistReactions, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.requirePlaylistAccess(dbs, uid, id, playlistAccessView, false)
+	if err != nil {
+		return nil, err
+	}
+	if !allowAuthor && playlist.Author.UserID == uid {
+		return nil, perr("you can't like your own playlist", http.StatusBadRequest)
+	}
+
+	err = react(dbs)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	reactions, err := s.pgdal.GetPlaylistReactions(dbs, id, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return reactions, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+func (s *Service) GetPlaylistRevisions(ctx context.Context, uid string, playlistID int64) ([]*types.PlaylistRevisionInfo, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	_, err = s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessEdit, true)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.pgdal.GetPlaylistRevisions(dbs, playlistID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	return revisions, nil
+}
+
+func (s *Service) DiffPlaylistRevisions(ctx context.Context, uid string, playlistID int64, from int64, to int64) (*types.PlaylistRevisionDiff, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	_, err = s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessEdit, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fromRevision, err := s.pgdal.GetPlaylistRevision(dbs, playlistID, from)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	toRevision, err := s.pgdal.GetPlaylistRevision(dbs, playlistID, to)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if fromRevision == nil || toRevision == nil {
+		return nil, perr("revision not found", http.StatusNotFound)
+	}
+
+	return diffPlaylistRevisions(fromRevision, toRevision), nil
+}
+
+// RestorePlaylistRevision puts the playlist back to an earlier revision, recording the restore as a new revision
+func (s *Service) RestorePlaylistRevision(ctx context.Context, uid string, playlistID int64, revision int64, fpfss types.IFpfss) (*types.Playlist, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	playlist, err := s.requirePlaylistAccess(dbs, uid, playlistID, playlistAccessEdit, true)
+	if err != nil {
+		return nil, err
+	}
+	old, err := s.pgdal.GetPlaylistRevision(dbs, playlistID, revision)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	if old == nil {
+		return nil, perr("revision not found", http.StatusNotFound)
+	}
+
+	// Restoring doesn't let an editor publish the playlist through an old public revision
+	access, staff, err := s.getPlaylistAccess(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	playlist.Name = old.Name
+	playlist.Description = old.Description
+	playlist.Library = old.Library
+	playlist.Icon = old.Icon
+	if access == playlistAccessOwner || staff {
+		playlist.Public = old.Public
+	}
+	playlist.Games = old.Games
+	playlist.TotalGames = len(old.Games)
+
+	// The author stays the same when an editor or moderator restores
+	err = s.pgdal.SavePlaylist(dbs, playlist.Author.UserID, playlist, fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	err = s.pgdal.CreatePlaylistRevision(dbs, uid, playlist)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	restored, err := s.pgdal.GetPlaylist(dbs, playlistID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	err = dbs.Commit()
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	utils.LogCtx(ctx).Infof("restored playlist %d to revision %d", playlistID, revision)
+	return restored, nil
+}
+
+func diffPlaylistRevisions(from *types.PlaylistRevision, to *types.PlaylistRevision) *types.PlaylistRevisionDiff {
+	diff := &types.PlaylistRevisionDiff{
+		From:         from.Revision,
+		To:           to.Revision,
+		Fields:       make([]*types.PlaylistFieldChange, 0),
+		Added:        make([]types.LauncherPlaylistGame, 0),
+		Removed:      make([]types.LauncherPlaylistGame, 0),
+		Reordered:    make([]*types.PlaylistGameMove, 0),
+		NotesChanged: make([]*types.PlaylistNotesChange, 0),
+	}
+
+	addField := func(field string, a string, b string) {
+		if a != b {
+			diff.Fields = append(diff.Fields, &types.PlaylistFieldChange{Field: field, From: a, To: b})
+		}
+	}
+	addField("name", from.Name, to.Name)
+	addField("description", from.Description, to.Description)
+	addField("library", from.Library, to.Library)
+	addField("public", strconv.FormatBool(from.Public), strconv.FormatBool(to.Public))
+	if from.Icon != to.Icon {
+		// Icons are image data, only say that it changed
+		diff.Fields = append(diff.Fields, &types.PlaylistFieldChange{Field: "icon"})
+	}
+
+	fromIndex := make(map[string]int)
+	for i, game := range from.Games {
+		fromIndex[strings.ToLower(game.GameID)] = i
+	}
+	toIndex := make(map[string]int)
+	for i, game := range to.Games {
+		toIndex[strings.ToLower(game.GameID)] = i
+	}
+
+	for _, game := range from.Games {
+		if _, ok := toIndex[strings.ToLower(game.GameID)]; !ok {
+			diff.Removed = append(diff.Removed, game)
+		}
+	}
+	common := make([]string, 0)
+	for _, game := range to.Games {
+		id := strings.ToLower(game.GameID)
+		i, ok := fromIndex[id]
+		if !ok {
+			diff.Added = append(diff.Added, game)
+			continue
+		}
+		common = append(common, id)
+		if from.Games[i].Notes != game.Notes {
+			diff.NotesChanged = append(diff.NotesChanged, &types.PlaylistNotesChange{
+				GameID: game.GameID,
+				From:   from.Games[i].Notes,
+				To:     game.Notes,
+			})
+		}
+	}
+
+	// Games kept in both revisions are in the new order in common. The longest run that is still in
+	// the old relative order stayed put, everything else counts as moved.
+	oldPositions := make([]int, len(common))
+	for i, id := range common {
+		oldPositions[i] = fromIndex[id]
+	}
+	stayed := make(map[string]bool)
+	for _, i := range longestIncreasingSubsequence(oldPositions) {
+		stayed[common[i]] = true
+	}
+	for _, id := range common {
+		if stayed[id] {
+			continue
+		}
+		diff.Reordered = append(diff.Reordered, &types.PlaylistGameMove{
+			GameID:       to.Games[toIndex[id]].GameID,
+			FromPosition: fromIndex[id],
+			ToPosition:   toIndex[id],
+		})
+	}
+
+	return diff
+}
+
+// longestIncreasingSubsequence returns the indexes of one longest strictly increasing subsequence of values
+func longestIncreasingSubsequence(values []int) []int {
+	// tails[k] is the index of the smallest value ending an increasing run of length k+1
+	tails := make([]int, 0)
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	if len(tails) == 0 {
+		return nil
+	}
+	res := make([]int, len(tails))
+	for i, k := tails[len(tails)-1], len(tails)-1; k >= 0; i, k = prev[i], k-1 {
+		res[k] = i
+	}
+	return res
+}
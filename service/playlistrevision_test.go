@@ -0,0 +1,179 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+)
+
+func TestLongestIncreasingSubsequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   []int
+	}{
+		{"empty", []int{}, nil},
+		{"single", []int{4}, []int{0}},
+		{"already sorted", []int{0, 1, 2, 3}, []int{0, 1, 2, 3}},
+		{"reversed", []int{3, 2, 1, 0}, []int{3}},
+		{"first moved to the end", []int{1, 2, 3, 0}, []int{0, 1, 2}},
+		{"last moved to the front", []int{3, 0, 1, 2}, []int{1, 2, 3}},
+		{"middle moved forward", []int{0, 3, 1, 2}, []int{0, 2, 3}},
+		{"swap at the end", []int{0, 1, 3, 2}, []int{0, 1, 3}},
+		{"repeated values are not increasing", []int{1, 1, 1}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := longestIncreasingSubsequence(tt.values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("longestIncreasingSubsequence(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i] <= got[i-1] || tt.values[got[i]] <= tt.values[got[i-1]] {
+					t.Fatalf("longestIncreasingSubsequence(%v) = %v is not strictly increasing", tt.values, got)
+				}
+			}
+		})
+	}
+}
+
+func revisionGames(ids ...string) []types.LauncherPlaylistGame {
+	games := make([]types.LauncherPlaylistGame, len(ids))
+	for i, id := range ids {
+		games[i] = types.LauncherPlaylistGame{GameID: id}
+	}
+	return games
+}
+
+func gameIDs(games []types.LauncherPlaylistGame) []string {
+	ids := make([]string, len(games))
+	for i, game := range games {
+		ids[i] = game.GameID
+	}
+	return ids
+}
+
+func TestDiffPlaylistRevisionsGames(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          []types.LauncherPlaylistGame
+		to            []types.LauncherPlaylistGame
+		wantAdded     []string
+		wantRemoved   []string
+		wantReordered []types.PlaylistGameMove
+		wantNotes     []types.PlaylistNotesChange
+	}{
+		{
+			name: "unchanged",
+			from: revisionGames("a", "b", "c"),
+			to:   revisionGames("a", "b", "c"),
+		},
+		{
+			name:        "added and removed",
+			from:        revisionGames("a", "b", "c"),
+			to:          revisionGames("a", "c", "d"),
+			wantAdded:   []string{"d"},
+			wantRemoved: []string{"b"},
+		},
+		{
+			name:          "last moved to the front is the only move",
+			from:          revisionGames("a", "b", "c", "d"),
+			to:            revisionGames("d", "a", "b", "c"),
+			wantReordered: []types.PlaylistGameMove{{GameID: "d", FromPosition: 3, ToPosition: 0}},
+		},
+		{
+			name:        "removal before a game doesn't count as moving it",
+			from:        revisionGames("a", "b", "c"),
+			to:          revisionGames("b", "c"),
+			wantRemoved: []string{"a"},
+		},
+		{
+			name: "swap",
+			from: revisionGames("a", "b"),
+			to:   revisionGames("b", "a"),
+			// Either game could be the one that moved, the search keeps the last of its new order in place
+			wantReordered: []types.PlaylistGameMove{{GameID: "b", FromPosition: 1, ToPosition: 0}},
+		},
+		{
+			name: "ids compare case-insensitively",
+			from: revisionGames("ABC", "def"),
+			to:   revisionGames("abc", "DEF"),
+		},
+		{
+			name: "notes changed",
+			from: []types.LauncherPlaylistGame{{GameID: "a", Notes: "old"}, {GameID: "b", Notes: "same"}},
+			to:   []types.LauncherPlaylistGame{{GameID: "a", Notes: "new"}, {GameID: "b", Notes: "same"}},
+			wantNotes: []types.PlaylistNotesChange{
+				{GameID: "a", From: "old", To: "new"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := diffPlaylistRevisions(
+				&types.PlaylistRevision{Revision: 1, Games: tt.from},
+				&types.PlaylistRevision{Revision: 2, Games: tt.to},
+			)
+			if diff.From != 1 || diff.To != 2 {
+				t.Errorf("diff is from %d to %d, want 1 to 2", diff.From, diff.To)
+			}
+			if len(diff.Fields) != 0 {
+				t.Errorf("fields = %v, want none", diff.Fields)
+			}
+			if got := gameIDs(diff.Added); !equalStrings(got, tt.wantAdded) {
+				t.Errorf("added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := gameIDs(diff.Removed); !equalStrings(got, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", got, tt.wantRemoved)
+			}
+			moves := make([]types.PlaylistGameMove, len(diff.Reordered))
+			for i, move := range diff.Reordered {
+				moves[i] = *move
+			}
+			if len(moves) != len(tt.wantReordered) || (len(moves) > 0 && !reflect.DeepEqual(moves, tt.wantReordered)) {
+				t.Errorf("reordered = %v, want %v", moves, tt.wantReordered)
+			}
+			notes := make([]types.PlaylistNotesChange, len(diff.NotesChanged))
+			for i, change := range diff.NotesChanged {
+				notes[i] = *change
+			}
+			if len(notes) != len(tt.wantNotes) || (len(notes) > 0 && !reflect.DeepEqual(notes, tt.wantNotes)) {
+				t.Errorf("notes changed = %v, want %v", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestDiffPlaylistRevisionsFields(t *testing.T) {
+	from := &types.PlaylistRevision{Revision: 3, Name: "Old", Description: "same", Library: "arcade", Icon: "a", Public: false}
+	to := &types.PlaylistRevision{Revision: 5, Name: "New", Description: "same", Library: "theatre", Icon: "b", Public: true}
+
+	diff := diffPlaylistRevisions(from, to)
+	want := []types.PlaylistFieldChange{
+		{Field: "name", From: "Old", To: "New"},
+		{Field: "library", From: "arcade", To: "theatre"},
+		{Field: "public", From: "false", To: "true"},
+		{Field: "icon"},
+	}
+	got := make([]types.PlaylistFieldChange, len(diff.Fields))
+	for i, change := range diff.Fields {
+		got[i] = *change
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+}
+
+// equalStrings compares slices treating nil and empty as equal
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// RunRoleSync resyncs active users' roles from FPFSS every interval until ctx is cancelled
+func (s *Service) RunRoleSync(ctx context.Context, fpfss types.IFpfss, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updated, revoked, err := s.SyncRoles(ctx, fpfss)
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Error("failed to sync roles")
+		} else if updated > 0 || revoked > 0 {
+			utils.LogCtx(ctx).Infof("synced roles, %d users updated and %d users revoked", updated, revoked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Some users leave the Discord server between runs, but when a sizeable share of lookups suddenly find nobody,
+// FPFSS or Discord is more likely misbehaving. Such runs still update roles but revoke no one.
+const (
+	roleSyncMaxMissingShare = 0.1
+	// roleSyncMinMissing departures are always allowed, so small sites can still revoke
+	roleSyncMinMissing = 3
+)
+
+// SyncRoles refetches the roles of every user with an active session or API token. Users who have left the
+// Discord server lose their roles, sessions and tokens. Failures for one user are logged and don't stop the others.
+// It returns how many users were updated and revoked.
+func (s *Service) SyncRoles(ctx context.Context, fpfss types.IFpfss) (int, int, error) {
+	ids, err := s.getActiveUserIDs(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Fetch everything outside of any transaction so a slow FPFSS never holds a connection open
+	userRoles := make(map[string][]*types.DiscordRole)
+	found := make([]string, 0, len(ids))
+	missing := make([]string, 0)
+	for _, uid := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+
+		user, err := fpfss.GetUserRoles(ctx, uid)
+		if err != nil {
+			// Never revoke on a failed lookup, the user is retried next run
+			utils.LogCtx(ctx).WithError(err).Warnf("failed to get roles of user %s", uid)
+			continue
+		}
+		if user == nil {
+			missing = append(missing, uid)
+			continue
+		}
+		userRoles[uid] = user.Roles
+		found = append(found, uid)
+	}
+
+	// Every role seen this run is stored once, the users only reference them by ID
+	roles := make([]*types.DiscordRole, 0)
+	seen := make(map[string]bool)
+	for _, uid := range found {
+		for _, role := range userRoles[uid] {
+			if !seen[role.ID] {
+				seen[role.ID] = true
+				roles = append(roles, role)
+			}
+		}
+	}
+	if err := s.saveRoles(ctx, roles); err != nil {
+		utils.LogCtx(ctx).WithError(err).Error("failed to save synced roles")
+	}
+
+	updated := 0
+	for _, uid := range found {
+		if ctx.Err() != nil {
+			break
+		}
+		roleIDs := make([]string, len(userRoles[uid]))
+		for i, role := range userRoles[uid] {
+			roleIDs[i] = role.ID
+		}
+		changed, err := s.updateUserRoles(ctx, uid, roleIDs)
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Errorf("failed to update roles of user %s", uid)
+			continue
+		}
+		if changed {
+			updated++
+		}
+	}
+
+	revoked := 0
+	allowed := int(roleSyncMaxMissingShare * float64(len(found)+len(missing)))
+	if allowed < roleSyncMinMissing {
+		allowed = roleSyncMinMissing
+	}
+	if len(missing) > allowed {
+		utils.LogCtx(ctx).Warnf("%d of %d users were not found on FPFSS, not revoking anyone this run", len(missing), len(found)+len(missing))
+		missing = nil
+	}
+	for _, uid := range missing {
+		if ctx.Err() != nil {
+			break
+		}
+		changed, err := s.updateUserRoles(ctx, uid, []string{})
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Errorf("failed to clear roles of user %s", uid)
+			continue
+		}
+		if changed {
+			updated++
+		}
+		if _, _, err := s.RevokeUserAccess(ctx, uid); err != nil {
+			utils.LogCtx(ctx).WithError(err).Errorf("failed to revoke access of user %s", uid)
+			continue
+		}
+		revoked++
+	}
+
+	// Rebuild the cache from the table so it picks up renamed and recoloured roles
+	if err := s.LoadRoles(ctx); err != nil {
+		return updated, revoked, err
+	}
+
+	return updated, revoked, nil
+}
+
+func (s *Service) getActiveUserIDs(ctx context.Context) ([]string, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	ids, err := s.pgdal.GetActiveUserIDs(dbs)
+	if err != nil {
+		return nil, dberr(err)
+	}
+
+	return ids, nil
+}
+
+func (s *Service) saveRoles(ctx context.Context, roles []*types.DiscordRole) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	if err = s.pgdal.SaveRoles(dbs, roles); err != nil {
+		return dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// updateUserRoles stores the user's role IDs, reporting whether they changed
+func (s *Service) updateUserRoles(ctx context.Context, uid string, roleIDs []string) (bool, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return false, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	changed, err := s.pgdal.UpdateUserRoles(dbs, uid, roleIDs)
+	if err != nil {
+		return false, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		return false, dberr(err)
+	}
+
+	return changed, nil
+}
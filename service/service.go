@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/FlashpointProject/CommunityWebsite/database"
 	"github.com/FlashpointProject/CommunityWebsite/types"
@@ -16,7 +17,10 @@ type Service struct {
 	pgdal                    database.PGDAL
 	authTokenProvider        AuthTokenizer
 	sessionExpirationSeconds int64
-	RoleCache                []*types.DiscordRole
+	sessionSecretKey         []byte
+	gotdMaxOpenSuggestions   int64
+	roleCache                []*types.DiscordRole
+	roleCacheLock            sync.RWMutex
 }
 
 type authTokenProvider struct {
@@ -41,11 +45,13 @@ func (a *authTokenProvider) CreateAuthToken(userID string) (*authToken, error) {
 	}, nil
 }
 
-func NewService(pgdb *pgxpool.Pool, sessionExpirationSeconds int64) *Service {
+func NewService(pgdb *pgxpool.Pool, sessionExpirationSeconds int64, sessionSecretKey string, gotdMaxOpenSuggestions int64) *Service {
 	return &Service{
 		pgdal:                    database.NewPostgresDAL(pgdb),
 		authTokenProvider:        NewAuthTokenProvider(),
 		sessionExpirationSeconds: sessionExpirationSeconds,
+		sessionSecretKey:         []byte(sessionSecretKey),
+		gotdMaxOpenSuggestions:   gotdMaxOpenSuggestions,
 	}
 }
 
@@ -57,7 +63,7 @@ func (s *Service) GetSessionAuthInfo(ctx context.Context, key string) (*types.Se
 	}
 	defer dbs.Rollback()
 
-	info, ok, err := s.pgdal.GetSessionAuthInfo(dbs, key)
+	info, ok, err := s.pgdal.GetSessionAuthInfo(dbs, s.hashSecret(key))
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		return nil, false, dberr(err)
@@ -101,15 +107,33 @@ func (s *Service) LoadRoles(ctx context.Context) error {
 		return nil
 	}
 
-	s.RoleCache = roles
+	s.roleCacheLock.Lock()
+	s.roleCache = roles
+	s.roleCacheLock.Unlock()
 
 	return nil
 }
 
+// RoleCache returns a copy of every known Discord role, safe to use while roles are being synced
+func (s *Service) RoleCache() []*types.DiscordRole {
+	s.roleCacheLock.RLock()
+	defer s.roleCacheLock.RUnlock()
+
+	roles := make([]*types.DiscordRole, len(s.roleCache))
+	for i, role := range s.roleCache {
+		r := *role
+		roles[i] = &r
+	}
+	return roles
+}
+
 func (s *Service) CacheRoles(roles []*types.DiscordRole) {
+	s.roleCacheLock.Lock()
+	defer s.roleCacheLock.Unlock()
+
 	for _, role := range roles {
 		found := false
-		for _, cachedRole := range s.RoleCache {
+		for _, cachedRole := range s.roleCache {
 			if role.ID == cachedRole.ID {
 				cachedRole.Name = role.Name
 				cachedRole.Color = role.Color
@@ -118,7 +142,8 @@ func (s *Service) CacheRoles(roles []*types.DiscordRole) {
 			}
 		}
 		if !found {
-			s.RoleCache = append(s.RoleCache, role)
+			r := *role
+			s.roleCache = append(s.roleCache, &r)
 		}
 	}
 }
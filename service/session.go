@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+)
+
+// GetUserSessions lists the user's active sessions, marking the one with ID currentID as current
+func (s *Service) GetUserSessions(ctx context.Context, uid string, currentID int64) ([]*types.SessionInfo, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	sessions, err := s.pgdal.GetUserSessions(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return nil, dberr(err)
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID == currentID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs the user out of one of their sessions
+func (s *Service) RevokeSession(ctx context.Context, uid string, id int64) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	found, err := s.pgdal.DeleteSession(dbs, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	if !found {
+		return perr("session not found", http.StatusNotFound)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions logs the user out everywhere, returning how many sessions were revoked
+func (s *Service) RevokeUserSessions(ctx context.Context, uid string) (int64, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	revoked, err := s.pgdal.DeleteUserSessions(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, dberr(err)
+	}
+
+	return revoked, nil
+}
+
+// RevokeUserAccess logs the user out everywhere and deletes their API tokens, returning how many of each were revoked
+func (s *Service) RevokeUserAccess(ctx context.Context, uid string) (int64, int64, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, 0, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	sessions, err := s.pgdal.DeleteUserSessions(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, 0, dberr(err)
+	}
+
+	tokens, err := s.pgdal.DeleteUserApiTokens(dbs, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, 0, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return 0, 0, dberr(err)
+	}
+
+	return sessions, tokens, nil
+}
+
+// Logout ends the session the secret belongs to. Unknown secrets are ignored, the caller is logged out either way.
+func (s *Service) Logout(ctx context.Context, secret string) error {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+	defer dbs.Rollback()
+
+	if err := s.pgdal.DeleteSessionBySecret(dbs, s.hashSecret(secret)); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		return dberr(err)
+	}
+
+	return nil
+}
+
+// RunSessionPurger deletes expired sessions and OAuth states every interval until ctx is cancelled
+func (s *Service) RunSessionPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeExpiredSessions(ctx)
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Error("failed to purge expired sessions")
+		} else if purged > 0 {
+			utils.LogCtx(ctx).Infof("purged %d expired sessions", purged)
+		}
+
+		purged, err = s.PurgeExpiredOAuthStates(ctx)
+		if err != nil {
+			utils.LogCtx(ctx).WithError(err).Error("failed to purge expired oauth states")
+		} else if purged > 0 {
+			utils.LogCtx(ctx).Infof("purged %d expired oauth states", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpiredSessions deletes every expired session, returning how many were deleted
+func (s *Service) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	dbs, err := s.pgdal.NewSession(ctx)
+	if err != nil {
+		return 0, dberr(err)
+	}
+	defer dbs.Rollback()
+
+	purged, err := s.pgdal.PurgeExpiredSessions(dbs)
+	if err != nil {
+		return 0, dberr(err)
+	}
+
+	if err := dbs.Commit(); err != nil {
+		return 0, dberr(err)
+	}
+
+	return purged, nil
+}
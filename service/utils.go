@@ -1,7 +1,61 @@
 package service
 
-import "github.com/FlashpointProject/CommunityWebsite/constants"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fpfssLookupBatchSize is how many game IDs are validated against FPFSS per request during an import
+const fpfssLookupBatchSize = 100
 
 func dberr(err error) error {
 	return constants.DatabaseError{Err: err}
 }
+
+func perr(msg string, status int) error {
+	return constants.PublicError{Msg: msg, Status: status}
+}
+
+// isUniqueViolation reports whether the statement failed on a unique constraint, e.g. when losing a race with a
+// concurrent insert that passed the same pre-check
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// findFpfssGames looks the IDs up on FPFSS in batches, returning the games found keyed by lowercased ID
+func findFpfssGames(ctx context.Context, ids []string, fpfss types.IFpfss) (map[string]*types.FpfssGame, error) {
+	found := make(map[string]*types.FpfssGame)
+	ids = utils.RemoveSliceDuplicates(ids)
+	for start := 0; start < len(ids); start += fpfssLookupBatchSize {
+		end := start + fpfssLookupBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		fpfssGames, err := fpfss.GetGames(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, game := range fpfssGames {
+			found[strings.ToLower(game.ID)] = game
+		}
+	}
+	return found, nil
+}
+
+// randomSecret returns 32 random bytes encoded as unpadded URL-safe base64
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+)
+
+func (a *App) GetApiTokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	tokens, err := a.Service.GetApiTokens(ctx, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.ApiTokensResponse{Tokens: tokens}, http.StatusOK)
+}
+
+func (a *App) CreateApiToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	var sub types.SubmittedApiToken
+	err := json.NewDecoder(r.Body).Decode(&sub)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	created, err := a.Service.CreateApiToken(ctx, uid, &sub)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, created, http.StatusCreated)
+}
+
+func (a *App) RevokeApiToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyApiTokenID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid token id", http.StatusBadRequest))
+		return
+	}
+
+	err = a.Service.RevokeApiToken(ctx, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
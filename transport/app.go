@@ -3,6 +3,7 @@ package transport
 import (
 	"github.com/FlashpointProject/CommunityWebsite/config"
 	"github.com/FlashpointProject/CommunityWebsite/service"
+	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
 )
 
@@ -10,5 +11,5 @@ type App struct {
 	Conf    *config.AppConfig
 	Service *service.Service
 	CC      utils.CookieCutter
-	Fpfss   *Fpfss
+	Fpfss   types.IFpfss
 }
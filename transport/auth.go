@@ -2,7 +2,10 @@ package transport
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,7 +13,6 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/FlashpointProject/CommunityWebsite/constants"
@@ -18,92 +20,31 @@ import (
 	"github.com/FlashpointProject/CommunityWebsite/service"
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
-	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 )
 
 var cookies = types.Cookies{
-	Login:     "login",
-	UserID:    "uid",
-	Username:  "username",
-	AvatarURL: "avatar_url",
-	Roles:     "roles",
+	Login:      "login",
+	UserID:     "uid",
+	Username:   "username",
+	AvatarURL:  "avatar_url",
+	Roles:      "roles",
+	OAuthState: "oauth_state",
 }
 
-type State struct {
-	Nonce       string `json:"nonce"`
-	RedirectURI string `json:"redirect_uri"`
-}
-
-type StateKeeper struct {
-	sync.Mutex
-	states            map[string]time.Time
-	expirationSeconds uint64
-}
-
-// Generate generates state and returns base64-encoded form
-func (sk *StateKeeper) Generate(redirectURI string) (string, error) {
-	sk.Clean()
-	u, err := uuid.NewV4()
-	if err != nil {
-		return "", err
-	}
-	s := &State{
-		Nonce:       u.String(),
-		RedirectURI: redirectURI,
-	}
-	sk.Lock()
-	sk.states[s.Nonce] = time.Now()
-	sk.Unlock()
-
-	j, err := json.Marshal(s)
-	if err != nil {
-		return "", err
-	}
-
-	b := base64.URLEncoding.EncodeToString(j)
-
-	return b, nil
-}
+// maxUserAgentLength caps the user agent stored with a session
+const maxUserAgentLength = 512
 
-// Consume consumes base64-encoded state and returns destination URL
-func (sk *StateKeeper) Consume(b string) (string, bool) {
-	sk.Clean()
-	sk.Lock()
-	defer sk.Unlock()
-
-	j, err := base64.URLEncoding.DecodeString(b)
-	if err != nil {
-		return "", false
-	}
-
-	s := &State{}
-
-	err = json.Unmarshal(j, s)
-	if err != nil {
-		return "", false
-	}
-
-	_, ok := sk.states[s.Nonce]
-	if ok {
-		delete(sk.states, s.Nonce)
-	}
-	return s.RedirectURI, ok
+// pkceChallenge derives the S256 PKCE code challenge from a code verifier
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
-func (sk *StateKeeper) Clean() {
-	sk.Lock()
-	defer sk.Unlock()
-	for k, v := range sk.states {
-		if v.After(v.Add(time.Duration(sk.expirationSeconds))) {
-			delete(sk.states, k)
-		}
-	}
-}
-
-var stateKeeper = StateKeeper{
-	states:            make(map[string]time.Time),
-	expirationSeconds: 30,
+// oauthStateHash is what the state cookie holds, tying a login to the browser that started it
+func oauthStateHash(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
 }
 
 func (a *App) OAuthLogin(w http.ResponseWriter, r *http.Request) {
@@ -125,19 +66,29 @@ func (a *App) OAuthLogin(w http.ResponseWriter, r *http.Request) {
 		cb_redirect = "/"
 	}
 
-	state, err := stateKeeper.Generate(cb_redirect)
+	// The state lives in the database so the callback can land on any instance
+	state, err := a.Service.CreateOAuthState(ctx, cb_redirect)
 	if err != nil {
 		utils.LogCtx(ctx).Error("failed to generate state")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	maxAge := int(time.Until(state.ExpiresAt).Seconds())
+	if err := a.CC.SetSecureCookie(w, cookies.OAuthState, map[string]string{"state": oauthStateHash(state.Nonce)}, maxAge); err != nil {
+		utils.LogCtx(ctx).Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	q := u.Query()
 	q.Add("response_type", "code")
 	q.Add("client_id", a.Conf.OauthConfig.ClientID)
 	q.Add("redirect_uri", a.Conf.OauthConfig.Callback)
 	q.Add("scope", a.Conf.OauthConfig.Scope)
-	q.Add("state", state)
+	q.Add("state", state.Nonce)
+	q.Add("code_challenge", pkceChallenge(state.CodeVerifier))
+	q.Add("code_challenge_method", "S256")
 	u.RawQuery = q.Encode()
 
 	http.Redirect(w, r, u.String(), http.StatusTemporaryRedirect)
@@ -156,13 +107,25 @@ func (a *App) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// verify state
+	// verify state, it must have been started by this browser
+	nonce := r.FormValue("state")
+	stateCookie, err := a.CC.GetSecureCookie(r, cookies.OAuthState)
+	utils.UnsetCookie(w, cookies.OAuthState)
+	if err != nil || subtle.ConstantTimeCompare([]byte(stateCookie["state"]), []byte(oauthStateHash(nonce))) != 1 {
+		writeError(ctx, w, perr("state does not match", http.StatusBadRequest))
+		return
+	}
 
-	redirectUri, ok := stateKeeper.Consume(r.FormValue("state"))
-	if !ok {
+	state, err := a.Service.ConsumeOAuthState(ctx, nonce)
+	if err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+	if state == nil {
 		writeError(ctx, w, perr("state does not match", http.StatusBadRequest))
 		return
 	}
+	redirectUri := state.RedirectURI
 	valid := isReturnURLLocal(redirectUri, a.Conf.HostBaseUrl)
 	if !valid {
 		redirectUri = "/"
@@ -174,6 +137,7 @@ func (a *App) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	data.Set("grant_type", "authorization_code")
 	data.Set("code", code)
 	data.Set("redirect_uri", a.Conf.OauthConfig.Callback)
+	data.Set("code_verifier", state.CodeVerifier)
 	// Use access token
 	req, err := http.NewRequest("POST", a.Conf.OauthConfig.TokenEndpoint, bytes.NewReader([]byte(data.Encode())))
 	if err != nil {
@@ -261,12 +225,16 @@ func (a *App) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user roles from FPFSS
-	flashpointUser, err := a.Fpfss.GetUserRoles(fpfssUser.ID)
+	flashpointUser, err := a.Fpfss.GetUserRoles(ctx, fpfssUser.ID)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, perr("failed to get user roles", http.StatusInternalServerError))
 		return
 	}
+	if flashpointUser == nil {
+		writeError(ctx, w, perr("you must be a member of the Flashpoint Discord server to log in", http.StatusForbidden))
+		return
+	}
 	fpfssUser.Roles = flashpointUser.Roles
 	fpfssUser.Color = flashpointUser.Color
 	if fpfssUser.Color == "#000000" {
@@ -274,9 +242,10 @@ func (a *App) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ipAddr := logging.RequestGetRemoteAddress(r)
+	userAgent := r.UserAgent()
 
 	// Make session, discard Discord auth token (not needed anymore)
-	authToken, err := a.Service.SaveUser(ctx, fpfssUser, ipAddr)
+	authToken, err := a.Service.SaveUser(ctx, fpfssUser, ipAddr, capString(maxUserAgentLength, &userAgent))
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, perr("failed to save user", http.StatusInternalServerError))
@@ -303,6 +272,32 @@ func (a *App) OAuthCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectUri, http.StatusFound)
 }
 
+// Logout ends the current session, if there is one, and clears the login cookies
+func (a *App) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	secret, ok := a.getSessionSecret(r)
+	if ok {
+		err := a.Service.Logout(ctx, secret)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			writeError(ctx, w, err)
+			return
+		}
+	}
+
+	unsetLoginCookies(w)
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+func unsetLoginCookies(w http.ResponseWriter) {
+	utils.UnsetCookie(w, cookies.Login)
+	utils.UnsetCookie(w, cookies.UserID)
+	utils.UnsetCookie(w, cookies.Username)
+	utils.UnsetCookie(w, cookies.AvatarURL)
+	utils.UnsetCookie(w, cookies.Roles)
+}
+
 func (a *App) GetProfile(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	uid := utils.UserID(ctx)
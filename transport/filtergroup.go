@@ -0,0 +1,156 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+)
+
+func (a *App) GetFilterGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	groups, err := a.Service.GetFilterGroups(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.ResponseFilterGroups{Groups: groups}, http.StatusOK)
+}
+
+func (a *App) CreateFilterGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sub, err := decodeFilterGroup(r)
+	if err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+
+	res, err := a.Service.CreateFilterGroup(ctx, sub)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, res, http.StatusCreated)
+}
+
+func (a *App) UpdateFilterGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyFilterGroup]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid filter group id", http.StatusBadRequest))
+		return
+	}
+
+	sub, err := decodeFilterGroup(r)
+	if err != nil {
+		writeError(ctx, w, err)
+		return
+	}
+
+	res, err := a.Service.UpdateFilterGroup(ctx, id, sub)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, res, http.StatusOK)
+}
+
+func (a *App) DeleteFilterGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyFilterGroup]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid filter group id", http.StatusBadRequest))
+		return
+	}
+
+	res, err := a.Service.DeleteFilterGroup(ctx, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, res, http.StatusOK)
+}
+
+func decodeFilterGroup(r *http.Request) (*types.SubmittedFilterGroup, error) {
+	var sub types.SubmittedFilterGroup
+	err := json.NewDecoder(r.Body).Decode(&sub)
+	if err != nil {
+		return nil, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest)
+	}
+
+	// Validate fields
+	if strings.TrimSpace(sub.Name) == "" {
+		return nil, perr("name is a required field", http.StatusBadRequest)
+	}
+	hasTag := false
+	for _, tag := range sub.Tags {
+		if strings.TrimSpace(tag) != "" {
+			hasTag = true
+			break
+		}
+	}
+	if !hasTag {
+		return nil, perr("tags must contain at least one tag", http.StatusBadRequest)
+	}
+	return &sub, nil
+}
+
+func (a *App) GetFilterPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	prefs, err := a.Service.GetFilterPreferences(ctx, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, prefs, http.StatusOK)
+}
+
+func (a *App) SaveFilterPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	var sub types.SubmittedFilterPreferences
+
+	err := json.NewDecoder(r.Body).Decode(&sub)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+	if sub.HiddenFilterGroups == nil {
+		writeError(ctx, w, perr("hidden_filter_groups is a required field", http.StatusBadRequest))
+		return
+	}
+
+	prefs, err := a.Service.SaveFilterPreferences(ctx, uid, &sub)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, prefs, http.StatusOK)
+}
@@ -7,12 +7,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/FlashpointProject/CommunityWebsite/config"
 	"github.com/FlashpointProject/CommunityWebsite/types"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	fpfssRequestTimeout  = 15 * time.Second
+	fpfssMaxAttempts     = 3
+	fpfssBaseBackoff     = 500 * time.Millisecond
+	fpfssMaxBackoff      = 10 * time.Second
+	fpfssTokenExpirySkew = 5 * time.Minute
 )
 
 type FpfssToken struct {
@@ -21,171 +33,181 @@ type FpfssToken struct {
 	ExpiresAt   time.Time `json:"expires_at"`
 }
 
+// FpfssCallStats holds counters for one kind of FPFSS call
+type FpfssCallStats struct {
+	Calls          int64 `json:"calls"`
+	Failures       int64 `json:"failures"`
+	Retries        int64 `json:"retries"`
+	TotalLatencyMs int64 `json:"total_latency_ms"`
+	MaxLatencyMs   int64 `json:"max_latency_ms"`
+}
+
 type Fpfss struct {
+	tokenLock   sync.RWMutex
 	token       *FpfssToken
+	tokenGroup  singleflight.Group
+	client      *http.Client
 	oauthConfig *config.OauthConfig
 	apiUrl      string
+	statsLock   sync.Mutex
+	stats       map[string]*FpfssCallStats
 }
 
 func NewFpfss(oauthConfig *config.OauthConfig, apiUrl string) (*Fpfss, error) {
 	r := &Fpfss{
+		client: &http.Client{
+			Timeout: fpfssRequestTimeout,
+		},
 		oauthConfig: oauthConfig,
 		apiUrl:      apiUrl,
+		stats:       make(map[string]*FpfssCallStats),
 	}
-	err := r.getNewToken()
+	_, err := r.GetToken(context.Background())
 	if err != nil {
 		return nil, err
 	}
 	return r, nil
 }
 
-func (f *Fpfss) WithFpfss(handler http.Handler) http.Handler {
+// WithFpfss makes the game metadata source available on every request context
+func WithFpfss(f types.IFpfss, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		r = r.WithContext(context.WithValue(r.Context(), CommCtxKeys.FPFSS, f))
 		handler.ServeHTTP(w, r)
 	})
 }
 
-func (f *Fpfss) GetToken() (string, error) {
-	// Validate token expiration
-	var err error
-	if f.token != nil {
-		if f.token.ExpiresAt.After(time.Now()) {
-			// Token already expired, get a new one
-			err = f.getNewToken()
-			if err != nil {
-				return "", err
-			}
-		}
-		if f.token.ExpiresAt.After(time.Now().Add((-60 * time.Minute))) {
-			// If expiring within the next hour, get a new one
-			err = f.getNewToken()
-			if err != nil {
-				return "", err
-			}
-		}
-		return f.token.AccessToken, nil
-	} else {
-		err = f.getNewToken()
-		if err != nil {
-			return "", err
+// GetToken returns a valid access token, fetching a new one if it is missing or about to expire.
+// Concurrent callers share a single refresh.
+func (f *Fpfss) GetToken(ctx context.Context) (string, error) {
+	f.tokenLock.RLock()
+	token := f.token
+	f.tokenLock.RUnlock()
+	if token != nil && token.ExpiresAt.After(time.Now().Add(fpfssTokenExpirySkew)) {
+		return token.AccessToken, nil
+	}
+
+	// Detached from ctx so one cancelled request doesn't fail the refresh for everyone waiting on it
+	ch := f.tokenGroup.DoChan("token", func() (interface{}, error) {
+		return f.getNewToken(context.Background())
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
 		}
-		return f.token.AccessToken, nil
+		return res.Val.(*FpfssToken).AccessToken, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
 }
 
-func (f *Fpfss) GetUserRoles(uid string) (*types.FlashpointDiscordUser, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/server-user/%s", f.apiUrl, uid), nil)
-	if err != nil {
-		return nil, err
-	}
-	token, err := f.GetToken()
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	var user *types.FlashpointDiscordUser
-	err = json.NewDecoder(resp.Body).Decode(&user)
-	if err != nil {
-		return nil, err
+// invalidateToken drops the current token if it is still the one that was rejected
+func (f *Fpfss) invalidateToken(accessToken string) {
+	f.tokenLock.Lock()
+	defer f.tokenLock.Unlock()
+	if f.token != nil && f.token.AccessToken == accessToken {
+		f.token = nil
 	}
-	return user, nil
 }
 
-func (f *Fpfss) getNewToken() error {
-	f.token = nil
-	// Get new token
+func (f *Fpfss) getNewToken(ctx context.Context) (*FpfssToken, error) {
 	authStr := fmt.Sprintf("%s:%s", f.oauthConfig.FpfssClientID, f.oauthConfig.FpfssClientSecret)
 	encodedAuth := base64.StdEncoding.EncodeToString([]byte(authStr))
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
 	data.Set("scope", f.oauthConfig.FpfssClientScope)
 	dataStr := data.Encode()
-	req, err := http.NewRequest("POST", f.oauthConfig.FpfssTokenEndpoint, bytes.NewReader([]byte(dataStr)))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", fmt.Sprintf("Basic %s", encodedAuth))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	client := &http.Client{}
-	resp, err := client.Do(req)
+
+	resp, err := f.do(ctx, "token", false, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", f.oauthConfig.FpfssTokenEndpoint, bytes.NewReader([]byte(dataStr)))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", encodedAuth))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		// get response body
 		msg, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("failed to get access token: %d - %s", resp.StatusCode, resp.Status)
+			return nil, fmt.Errorf("failed to get access token: %d - %s", resp.StatusCode, resp.Status)
 		} else {
-			return fmt.Errorf("failed to get access token: %d - %s - %s", resp.StatusCode, resp.Status, string(msg))
+			return nil, fmt.Errorf("failed to get access token: %d - %s - %s", resp.StatusCode, resp.Status, string(msg))
 		}
 	}
 	var tokenRes *types.AuthTokenResponse
 	err = json.NewDecoder(resp.Body).Decode(&tokenRes)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	f.token = &FpfssToken{
+	token := &FpfssToken{
 		AccessToken: tokenRes.AccessToken,
 		TokenType:   tokenRes.TokenType,
 		ExpiresAt:   time.Now().Add(time.Duration(tokenRes.ExpiresIn) * time.Second),
 	}
 
-	return nil
+	f.tokenLock.Lock()
+	f.token = token
+	f.tokenLock.Unlock()
+
+	return token, nil
 }
 
-func (f *Fpfss) GetGames(ids []string) ([]*types.FpfssGame, error) {
-	data, err := json.Marshal(map[string]interface{}{"game_ids": ids})
+func (f *Fpfss) GetUserRoles(ctx context.Context, uid string) (*types.FlashpointDiscordUser, error) {
+	resp, err := f.do(ctx, "server-user", true, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/server-user/%s", f.apiUrl, uid), nil)
+	})
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/games/fetch", f.apiUrl), bytes.NewReader(data))
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get user roles: %d - %s", resp.StatusCode, resp.Status)
+	}
+	var user *types.FlashpointDiscordUser
+	err = json.NewDecoder(resp.Body).Decode(&user)
 	if err != nil {
 		return nil, err
 	}
-	token, err := f.GetToken()
+	return user, nil
+}
+
+func (f *Fpfss) GetGames(ctx context.Context, ids []string) ([]*types.FpfssGame, error) {
+	data, err := json.Marshal(map[string]interface{}{"game_ids": ids})
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("token: ", token)
-	req.Header.Set("Authorization", "Bearer "+token)
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := f.do(ctx, "games-fetch", true, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/api/games/fetch", f.apiUrl), bytes.NewReader(data))
+	})
 	if err != nil {
-		fmt.Printf("failed to get response: %s", err.Error())
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get games: %d - %s", resp.StatusCode, resp.Status)
+	}
 	var respData *types.ResponseFpfssGamesFetch
 	err = json.NewDecoder(resp.Body).Decode(&respData)
 	if err != nil {
-		fmt.Printf("failed to get response: %s", err.Error())
-		return nil, err
+		return nil, fmt.Errorf("failed to decode games: %w", err)
 	}
 	return respData.Games, nil
 }
 
-func (f *Fpfss) GetGame(id string) (*types.FpfssGame, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/game/%s", f.apiUrl, id), nil)
-	if err != nil {
-		return nil, err
-	}
-	token, err := f.GetToken()
-	if err != nil {
-		return nil, err
-	}
-	fmt.Println("token: ", token)
-	req.Header.Set("Authorization", "Bearer "+token)
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (f *Fpfss) GetGame(ctx context.Context, id string) (*types.FpfssGame, error) {
+	resp, err := f.do(ctx, "game", true, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/game/%s", f.apiUrl, id), nil)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -203,3 +225,164 @@ func (f *Fpfss) GetGame(id string) (*types.FpfssGame, error) {
 	}
 	return game, nil
 }
+
+// SearchGames asks FPFSS for games matching the search
+func (f *Fpfss) SearchGames(ctx context.Context, search *types.FpfssGameSearch) ([]*types.FpfssGame, error) {
+	params := url.Values{}
+	for key, value := range map[string]string{
+		"title":     search.Title,
+		"developer": search.Developer,
+		"publisher": search.Publisher,
+		"series":    search.Series,
+		"platform":  search.Platform,
+		"tag":       search.Tag,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if search.Limit > 0 {
+		params.Set("limit", strconv.FormatInt(search.Limit, 10))
+	}
+	resp, err := f.do(ctx, "games-search", true, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/games/search?%s", f.apiUrl, params.Encode()), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search games: %d - %s", resp.StatusCode, resp.Status)
+	}
+	var respData *types.ResponseFpfssGamesFetch
+	err = json.NewDecoder(resp.Body).Decode(&respData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode games: %w", err)
+	}
+	return respData.Games, nil
+}
+
+// Metrics returns a snapshot of the call counters, keyed by call name
+func (f *Fpfss) Metrics() map[string]FpfssCallStats {
+	f.statsLock.Lock()
+	defer f.statsLock.Unlock()
+	res := make(map[string]FpfssCallStats, len(f.stats))
+	for name, stats := range f.stats {
+		res[name] = *stats
+	}
+	return res
+}
+
+// do sends a request built by newReq, retrying with backoff on network errors, 429 and 5xx responses.
+// A fresh request is built per attempt so bodies can be replayed. With auth set, the bearer token is
+// attached and a 401 triggers one token refresh.
+func (f *Fpfss) do(ctx context.Context, name string, auth bool, newReq func(context.Context) (*http.Request, error)) (*http.Response, error) {
+	start := time.Now()
+	retries := int64(0)
+	refreshedToken := false
+
+	resp, err := func() (*http.Response, error) {
+		for attempt := 1; ; attempt++ {
+			req, err := newReq(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var token string
+			if auth {
+				token, err = f.GetToken(ctx)
+				if err != nil {
+					return nil, err
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			resp, err := f.client.Do(req)
+			var wait time.Duration
+			switch {
+			case err != nil:
+				if ctx.Err() != nil || attempt >= fpfssMaxAttempts {
+					return nil, err
+				}
+				wait = fpfssBackoff(attempt)
+			case auth && resp.StatusCode == http.StatusUnauthorized && !refreshedToken:
+				resp.Body.Close()
+				f.invalidateToken(token)
+				refreshedToken = true
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+				if attempt >= fpfssMaxAttempts {
+					return resp, nil
+				}
+				wait = fpfssRetryAfter(resp, attempt)
+				resp.Body.Close()
+			default:
+				return resp, nil
+			}
+
+			retries++
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}()
+
+	failed := err != nil || resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound
+	f.record(name, time.Since(start), retries, failed)
+	return resp, err
+}
+
+func (f *Fpfss) record(name string, latency time.Duration, retries int64, failed bool) {
+	f.statsLock.Lock()
+	defer f.statsLock.Unlock()
+	stats, ok := f.stats[name]
+	if !ok {
+		stats = &FpfssCallStats{}
+		f.stats[name] = stats
+	}
+	ms := latency.Milliseconds()
+	stats.Calls++
+	stats.Retries += retries
+	stats.TotalLatencyMs += ms
+	if ms > stats.MaxLatencyMs {
+		stats.MaxLatencyMs = ms
+	}
+	if failed {
+		stats.Failures++
+	}
+}
+
+// fpfssBackoff is exponential backoff with jitter
+func fpfssBackoff(attempt int) time.Duration {
+	d := fpfssBaseBackoff << (attempt - 1)
+	if d > fpfssMaxBackoff {
+		d = fpfssMaxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// fpfssRetryAfter honours a Retry-After header given in seconds, falling back to backoff
+func fpfssRetryAfter(resp *http.Response, attempt int) time.Duration {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+		d := time.Duration(secs) * time.Second
+		if d > fpfssMaxBackoff {
+			d = fpfssMaxBackoff
+		}
+		return d
+	}
+	return fpfssBackoff(attempt)
+}
+
+// GetFpfssMetrics reports FPFSS call counters, empty when the game source doesn't track them
+func (a *App) GetFpfssMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	metrics := make(map[string]FpfssCallStats)
+	if f, ok := a.Fpfss.(*Fpfss); ok {
+		metrics = f.Metrics()
+	}
+	writeResponse(ctx, w, metrics, http.StatusOK)
+}
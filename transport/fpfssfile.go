@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+)
+
+// FileFpfss serves game metadata and user roles from a local dump instead of FPFSS,
+// so the site can run offline for development and integration tests
+type FileFpfss struct {
+	games map[string]*types.FpfssGame
+	users map[string]*types.FlashpointDiscordUser
+}
+
+func NewFileFpfss(path string) (*FileFpfss, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var dump types.FpfssDump
+	err = json.Unmarshal(data, &dump)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode fpfss dump: %w", err)
+	}
+
+	f := &FileFpfss{
+		games: make(map[string]*types.FpfssGame),
+		users: make(map[string]*types.FlashpointDiscordUser),
+	}
+	for _, game := range dump.Games {
+		f.games[strings.ToLower(game.ID)] = game
+	}
+	for _, user := range dump.Users {
+		f.users[user.ID] = user
+	}
+	return f, nil
+}
+
+func (f *FileFpfss) GetGame(ctx context.Context, id string) (*types.FpfssGame, error) {
+	game, ok := f.games[strings.ToLower(id)]
+	if !ok {
+		return nil, nil
+	}
+	return game, nil
+}
+
+func (f *FileFpfss) GetGames(ctx context.Context, ids []string) ([]*types.FpfssGame, error) {
+	games := make([]*types.FpfssGame, 0)
+	for _, id := range ids {
+		if game, ok := f.games[strings.ToLower(id)]; ok {
+			games = append(games, game)
+		}
+	}
+	return games, nil
+}
+
+// SearchGames matches the dump's games the same way FPFSS does, ordered by title
+func (f *FileFpfss) SearchGames(ctx context.Context, search *types.FpfssGameSearch) ([]*types.FpfssGame, error) {
+	contains := func(value string, sub string) bool {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(sub))
+	}
+	games := make([]*types.FpfssGame, 0)
+	for _, game := range f.games {
+		if !contains(game.Title, search.Title) || !contains(game.Developer, search.Developer) ||
+			!contains(game.Publisher, search.Publisher) || !contains(game.Series, search.Series) {
+			continue
+		}
+		if search.Platform != "" && !strings.EqualFold(game.Platform, search.Platform) {
+			continue
+		}
+		if search.Tag != "" {
+			found := false
+			for _, tag := range game.Tags {
+				if strings.EqualFold(tag.Name, search.Tag) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		games = append(games, game)
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return strings.ToLower(games[i].Title) < strings.ToLower(games[j].Title)
+	})
+	if search.Limit > 0 && int64(len(games)) > search.Limit {
+		games = games[:search.Limit]
+	}
+	return games, nil
+}
+
+// GetUserRoles returns the user's roles from the dump, users not listed are treated as not in the Discord server
+func (f *FileFpfss) GetUserRoles(ctx context.Context, uid string) (*types.FlashpointDiscordUser, error) {
+	user, ok := f.users[uid]
+	if !ok {
+		return nil, nil
+	}
+	return user, nil
+}
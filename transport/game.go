@@ -0,0 +1,82 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+const maxGameSearchPageSize = 100
+
+// GetGameCommunityPage returns the game with its playlists, Game of the Day history, open suggestions and related games
+func (a *App) GetGameCommunityPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	id := params[constants.ResourceKeyGameID]
+
+	if len(id) != 36 {
+		writeError(ctx, w, perr("invalid game id", http.StatusBadRequest))
+		return
+	}
+
+	page, err := a.Service.GetGameCommunityPage(ctx, utils.UserID(ctx), includeExtreme(r), id, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, page, http.StatusOK)
+}
+
+// SearchGames searches cached games by metadata, or autocompletes titles when autocomplete is set
+func (a *App) SearchGames(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := r.ParseForm()
+	if err != nil {
+		writeError(ctx, w, perr("failed to parse form", http.StatusBadRequest))
+		return
+	}
+
+	var query types.GameSearchQuery
+	err = schema.NewDecoder().Decode(&query, r.Form)
+	if err != nil {
+		writeError(ctx, w, perr(fmt.Sprintf("failed to decode form: %s", err.Error()), http.StatusBadRequest))
+		return
+	}
+
+	// Falling back to FPFSS costs remote calls, so it's kept to logged in users
+	if query.Fpfss && utils.UserID(ctx) == "" {
+		writeError(ctx, w, perr("you must be logged in to search FPFSS", http.StatusUnauthorized))
+		return
+	}
+	if query.Autocomplete && strings.TrimSpace(query.Title) == "" {
+		writeError(ctx, w, perr("title is required for autocomplete", http.StatusBadRequest))
+		return
+	}
+	query.Title = strings.TrimSpace(query.Title)
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = 10
+	}
+	if query.PageSize > maxGameSearchPageSize {
+		query.PageSize = maxGameSearchPageSize
+	}
+
+	res, err := a.Service.SearchGames(ctx, utils.UserID(ctx), &query, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, res, http.StatusOK)
+}
@@ -1,14 +1,26 @@
 package transport
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/schema"
 )
 
+const maxGotdImportSize = 10 << 20
+
 func (a *App) SearchGotdSuggestions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	err := r.ParseForm()
@@ -31,7 +43,7 @@ func (a *App) SearchGotdSuggestions(w http.ResponseWriter, r *http.Request) {
 		query.PageSize = 10
 	}
 
-	suggestions, total, err := a.Service.SearchGotdSuggestions(ctx, &query, a.Fpfss)
+	suggestions, total, err := a.Service.SearchGotdSuggestions(ctx, utils.UserID(ctx), &query, a.Fpfss)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, err)
@@ -45,3 +57,274 @@ func (a *App) SearchGotdSuggestions(w http.ResponseWriter, r *http.Request) {
 
 	writeResponse(ctx, w, res, http.StatusOK)
 }
+
+func (a *App) GetGotdCurrent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	games, err := a.Service.GetGotdCurrent(ctx, &types.GetGotdCurrentQuery{ShowFuture: false})
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.GotdScheduleResponse{Games: games}, http.StatusOK)
+}
+
+func (a *App) GetGotdSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := r.ParseForm()
+	if err != nil {
+		writeError(ctx, w, perr("failed to parse form", http.StatusBadRequest))
+		return
+	}
+
+	var query types.GetGotdCurrentQuery
+	err = schema.NewDecoder().Decode(&query, r.Form)
+	if err != nil {
+		writeError(ctx, w, perr(fmt.Sprintf("failed to decode form: %s", err.Error()), http.StatusBadRequest))
+		return
+	}
+
+	games, err := a.Service.GetGotdCurrent(ctx, &query)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.GotdScheduleResponse{Games: games}, http.StatusOK)
+}
+
+func (a *App) AssignGotd(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	var req types.GotdAssignRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	if req.SuggestionID == 0 {
+		writeError(ctx, w, perr("suggestion_id is a required field", http.StatusBadRequest))
+		return
+	}
+	if _, err := time.Parse(constants.GotdDateFormat, req.Date); err != nil {
+		writeError(ctx, w, perr("date must be in YYYY-MM-DD format", http.StatusBadRequest))
+		return
+	}
+
+	err = a.Service.AssignGotd(ctx, uid, req.SuggestionID, req.Date, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+func (a *App) UnassignGotd(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	date := params[constants.ResourceKeyGotdDate]
+
+	if _, err := time.Parse(constants.GotdDateFormat, date); err != nil {
+		writeError(ctx, w, perr("date must be in YYYY-MM-DD format", http.StatusBadRequest))
+		return
+	}
+
+	err := a.Service.UnassignGotd(ctx, uid, date)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+func (a *App) SubmitGotdSuggestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	var sub types.SubmittedGotdSuggestion
+
+	err := json.NewDecoder(r.Body).Decode(&sub)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	// Validate fields
+	if len(sub.GameID) != 36 {
+		writeError(ctx, w, perr("invalid game id", http.StatusBadRequest))
+		return
+	}
+	if sub.Description == "" {
+		writeError(ctx, w, perr("description is a required field", http.StatusBadRequest))
+		return
+	}
+	var suggestedDate *time.Time
+	if sub.SuggestedDate != "" {
+		date, err := time.Parse(constants.GotdDateFormat, sub.SuggestedDate)
+		if err != nil {
+			writeError(ctx, w, perr("suggested_date must be in YYYY-MM-DD format", http.StatusBadRequest))
+			return
+		}
+		if !date.After(time.Now()) {
+			writeError(ctx, w, perr("suggested_date must be in the future", http.StatusBadRequest))
+			return
+		}
+		suggestedDate = &date
+	}
+
+	suggestion := &types.GotdSuggestionInternal{
+		Game: &types.CachedGame{
+			ID: sub.GameID,
+		},
+		Anonymous:     sub.Anonymous,
+		Description:   sub.Description,
+		SuggestedDate: suggestedDate,
+	}
+
+	res, err := a.Service.SubmitGotdSuggestion(ctx, uid, suggestion, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, res, http.StatusOK)
+}
+
+func (a *App) DeleteGotdSuggestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeySuggestion]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid suggestion id", http.StatusBadRequest))
+		return
+	}
+
+	err = a.Service.DeleteGotdSuggestion(ctx, uid, id, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+func (a *App) GetGotdFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	file, lastModified, err := a.Service.GetGotdFile(ctx)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, perr("failed to marshal gotd file", http.StatusInternalServerError))
+		return
+	}
+
+	// ServeContent answers conditional requests with 304 using these
+	hash := sha256.Sum256(data)
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:16])))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeContent(w, r, "gotd.json", lastModified, bytes.NewReader(data))
+}
+
+// ImportGotdFile accepts a launcher gotd.json either as a multipart "file" upload or as the raw request body
+func (a *App) ImportGotdFile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	r.Body = http.MaxBytesReader(w, r.Body, maxGotdImportSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(ctx, w, perr("failed to read uploaded file - "+err.Error(), http.StatusBadRequest))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	var file types.GotdFile
+	err := json.NewDecoder(body).Decode(&file)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode gotd file - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	overwrite := r.URL.Query().Get("overwrite") == "true"
+
+	result, err := a.Service.ImportGotdFile(ctx, uid, &file, overwrite, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, result, http.StatusOK)
+}
+
+func (a *App) PreviewGotdSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := r.ParseForm()
+	if err != nil {
+		writeError(ctx, w, perr("failed to parse form", http.StatusBadRequest))
+		return
+	}
+
+	var query types.GotdAutoScheduleQuery
+	err = schema.NewDecoder().Decode(&query, r.Form)
+	if err != nil {
+		writeError(ctx, w, perr(fmt.Sprintf("failed to decode form: %s", err.Error()), http.StatusBadRequest))
+		return
+	}
+
+	plan, err := a.Service.PreviewGotdSchedule(ctx, &query, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, plan, http.StatusOK)
+}
+
+func (a *App) ApplyGotdSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	var query types.GotdAutoScheduleQuery
+
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	plan, err := a.Service.ApplyGotdSchedule(ctx, uid, &query, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, plan, http.StatusOK)
+}
@@ -2,13 +2,14 @@ package transport
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/service"
+	"github.com/FlashpointProject/CommunityWebsite/types"
 	"github.com/FlashpointProject/CommunityWebsite/utils"
 )
 
@@ -29,56 +30,23 @@ func (a *App) UserAuthMux(next func(http.ResponseWriter, *http.Request), authori
 
 		}
 
-		var secret string
-		var err error
-		authHeader := r.Header.Get("Authorization")
-		if authHeader != "" {
-			// try bearer token
-			// split the header at the space character
-			authHeaderParts := strings.Split(authHeader, " ")
-			if len(authHeaderParts) != 2 || authHeaderParts[0] != "Bearer" {
-				handleAuthErr()
-				return
-			}
-			decodedBytes, err := base64.StdEncoding.DecodeString(authHeaderParts[1])
-			if err != nil {
-				handleAuthErr()
-				return
-			}
-			var tokenMap map[string]string
-			err = json.Unmarshal(decodedBytes, &tokenMap)
-			if err != nil {
-				handleAuthErr()
-				return
-			}
-			token, err := service.ParseAuthToken(tokenMap)
-			if err != nil {
-				handleAuthErr()
-				return
-			}
-			secret = token.Secret
-		} else {
-			// try cookie
-			secret, err = a.GetSecretFromCookie(ctx, r)
-			if err != nil {
-				handleAuthErr()
-				return
-			}
-		}
-
-		authInfo, ok, err := a.Service.GetSessionAuthInfo(ctx, secret)
-		if err != nil {
+		authInfo, ok := a.getSessionAuthInfo(w, r)
+		if !ok {
 			handleAuthErr()
 			return
 		}
-		if !ok {
-			handleAuthErr()
+
+		if !tokenHasScope(ctx, authInfo) {
+			if utils.RequiredScope(ctx) == "" {
+				writeError(ctx, w, perr("this page can't be accessed with an API token", http.StatusForbidden))
+			} else {
+				writeError(ctx, w, perr(fmt.Sprintf("this API token needs the '%s' scope", utils.RequiredScope(ctx)), http.StatusForbidden))
+			}
 			return
 		}
 
 		if len(authorizers) == 0 {
-			r = r.WithContext(context.WithValue(ctx, utils.CtxKeys.UserID, authInfo.UID))
-			next(w, r)
+			next(w, r.WithContext(withSession(ctx, authInfo)))
 			return
 		}
 
@@ -98,8 +66,7 @@ func (a *App) UserAuthMux(next func(http.ResponseWriter, *http.Request), authori
 		}
 
 		if allOk {
-			r = r.WithContext(context.WithValue(ctx, utils.CtxKeys.UserID, authInfo.UID))
-			next(w, r)
+			next(w, r.WithContext(withSession(ctx, authInfo)))
 			return
 		}
 
@@ -108,6 +75,116 @@ func (a *App) UserAuthMux(next func(http.ResponseWriter, *http.Request), authori
 	}
 }
 
+// OptionalAuthMux identifies the user when the request carries a valid session, otherwise continues anonymously.
+// An API token without the route's scope is treated as anonymous.
+func (a *App) OptionalAuthMux(next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authInfo, ok := a.getSessionAuthInfo(w, r)
+		if ok && tokenHasScope(r.Context(), authInfo) {
+			r = r.WithContext(withSession(r.Context(), authInfo))
+		}
+		next(w, r)
+	}
+}
+
+// TokenScope lets personal API tokens with the given scope use the route. Routes without one only accept sessions.
+func (a *App) TokenScope(scope string, next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(context.WithValue(r.Context(), utils.CtxKeys.RequiredScope, scope)))
+	}
+}
+
+// tokenHasScope reports whether an API token was granted the route's scope, sessions always pass
+func tokenHasScope(ctx context.Context, authInfo *types.SessionInfo) bool {
+	if authInfo.TokenID == 0 {
+		return true
+	}
+	scope := utils.RequiredScope(ctx)
+	return scope != "" && utils.StringInSlice(scope, authInfo.Scopes)
+}
+
+// withSession stores the authenticated user and session on the request context
+func withSession(ctx context.Context, authInfo *types.SessionInfo) context.Context {
+	ctx = context.WithValue(ctx, utils.CtxKeys.UserID, authInfo.UID)
+	if authInfo.TokenID != 0 {
+		return context.WithValue(ctx, utils.CtxKeys.Scope, strings.Join(authInfo.Scopes, " "))
+	}
+	return context.WithValue(ctx, utils.CtxKeys.SessionID, authInfo.ID)
+}
+
+// getSessionAuthInfo finds the session from the API token or login cookie, reporting false if there is no valid one.
+// Cookie sessions issued before the user's roles last changed are given a new secret.
+func (a *App) getSessionAuthInfo(w http.ResponseWriter, r *http.Request) (*types.SessionInfo, bool) {
+	ctx := r.Context()
+	if r.Header.Get("Authorization") != "" {
+		// Personal API tokens are the only bearer tokens accepted
+		token, ok := getApiToken(r)
+		if !ok {
+			return nil, false
+		}
+		authInfo, ok, err := a.Service.GetApiTokenAuthInfo(ctx, token)
+		if err != nil || !ok {
+			return nil, false
+		}
+		return authInfo, true
+	}
+
+	secret, ok := a.getSessionSecret(r)
+	if !ok {
+		return nil, false
+	}
+
+	authInfo, ok, err := a.Service.GetSessionAuthInfo(ctx, secret)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	if authInfo.RotationRequired {
+		a.rotateSession(w, r, authInfo, secret)
+	}
+
+	return authInfo, true
+}
+
+// rotateSession replaces the session's secret and sends the new login cookie. Failing to rotate doesn't fail
+// the request, authorization always reads the user's current roles.
+func (a *App) rotateSession(w http.ResponseWriter, r *http.Request, authInfo *types.SessionInfo, secret string) {
+	ctx := r.Context()
+	authToken, err := a.Service.RotateSession(ctx, authInfo, secret)
+	if err != nil {
+		utils.LogCtx(ctx).WithError(err).Warn("failed to rotate session")
+		return
+	}
+	if authToken == nil {
+		return
+	}
+
+	maxAge := int(time.Until(authInfo.ExpiresAt).Seconds())
+	if err := a.CC.SetSecureCookie(w, cookies.Login, service.MapAuthToken(authToken), maxAge); err != nil {
+		utils.LogCtx(ctx).WithError(err).Warn("failed to set rotated session cookie")
+		return
+	}
+	SetCookie(w, cookies.Roles, strings.Join(authInfo.Roles, ","), maxAge)
+}
+
+// getApiToken reads a personal API token from the bearer token
+func getApiToken(r *http.Request) (string, bool) {
+	authHeaderParts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(authHeaderParts) != 2 || authHeaderParts[0] != "Bearer" || !strings.HasPrefix(authHeaderParts[1], constants.ApiTokenPrefix) {
+		return "", false
+	}
+	return authHeaderParts[1], true
+}
+
+// getSessionSecret reads the session secret from the login cookie
+func (a *App) getSessionSecret(r *http.Request) (string, bool) {
+	secret, err := a.GetSecretFromCookie(r.Context(), r)
+	if err != nil {
+		return "", false
+	}
+	return secret, true
+}
+
 func (a *App) RequestJSON(next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		next(w, r.WithContext(context.WithValue(r.Context(), utils.CtxKeys.RequestType, constants.RequestJSON)))
@@ -3,8 +3,10 @@ package transport
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/FlashpointProject/CommunityWebsite/constants"
 	"github.com/FlashpointProject/CommunityWebsite/types"
@@ -13,7 +15,19 @@ import (
 	"github.com/gorilla/schema"
 )
 
+// maxPlaylistImportSize caps uploaded launcher playlist files
+const maxPlaylistImportSize = 10 << 20
+
 func (a *App) SearchPlaylists(w http.ResponseWriter, r *http.Request) {
+	a.searchPlaylists(w, r, "")
+}
+
+// GetBookmarkedPlaylists searches the user's bookmarked playlists, taking the same parameters as SearchPlaylists
+func (a *App) GetBookmarkedPlaylists(w http.ResponseWriter, r *http.Request) {
+	a.searchPlaylists(w, r, utils.UserID(r.Context()))
+}
+
+func (a *App) searchPlaylists(w http.ResponseWriter, r *http.Request, bookmarkedBy string) {
 	ctx := r.Context()
 	err := r.ParseForm()
 	if err != nil {
@@ -34,8 +48,9 @@ func (a *App) SearchPlaylists(w http.ResponseWriter, r *http.Request) {
 	if query.PageSize == 0 {
 		query.PageSize = 10
 	}
+	query.BookmarkedBy = bookmarkedBy
 
-	playlists, total, err := a.Service.SearchPlaylists(ctx, &query)
+	playlists, total, facets, err := a.Service.SearchPlaylists(ctx, utils.UserID(ctx), &query)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, err)
@@ -54,6 +69,9 @@ func (a *App) SearchPlaylists(w http.ResponseWriter, r *http.Request) {
 			Public:       playlist.Public,
 			Extreme:      playlist.Extreme,
 			FilterGroups: playlist.FilterGroups,
+			ForkedFrom:   playlist.ForkedFrom,
+			ForkCount:    playlist.ForkCount,
+			Likes:        playlist.Likes,
 			CreatedAt:    playlist.CreatedAt,
 			UpdatedAt:    playlist.UpdatedAt,
 		}
@@ -62,6 +80,7 @@ func (a *App) SearchPlaylists(w http.ResponseWriter, r *http.Request) {
 	res := &types.PlaylistSearchResponse{
 		Playlists: playlistInfos,
 		Total:     total,
+		Facets:    facets,
 	}
 
 	writeResponse(ctx, w, res, http.StatusOK)
@@ -69,12 +88,7 @@ func (a *App) SearchPlaylists(w http.ResponseWriter, r *http.Request) {
 
 func (a *App) GetRoles(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	writeResponse(ctx, w, a.Service.RoleCache, http.StatusOK)
-}
-
-func (a *App) GetFilterGroups(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	writeResponse(ctx, w, &constants.ResponseFilterGroups{Groups: constants.GetFilterGroups()}, http.StatusOK)
+	writeResponse(ctx, w, a.Service.RoleCache(), http.StatusOK)
 }
 
 func (a *App) GetUserSuggestions(w http.ResponseWriter, r *http.Request) {
@@ -92,7 +106,7 @@ func (a *App) GetPlaylistPreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	playlist, err := a.Service.GetDownloadablePlaylist(ctx, id)
+	playlist, err := a.Service.GetDownloadablePlaylist(ctx, utils.UserID(ctx), id)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, perr("failed to get playlist", http.StatusInternalServerError))
@@ -118,7 +132,7 @@ func (a *App) GetPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	playlist, err := a.Service.GetPlaylist(ctx, id, a.Fpfss)
+	playlist, err := a.Service.GetPlaylist(ctx, utils.UserID(ctx), includeExtreme(r), id, a.Fpfss)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
 		writeError(ctx, w, perr("failed to get playlist", http.StatusInternalServerError))
@@ -133,6 +147,7 @@ func (a *App) GetPlaylist(w http.ResponseWriter, r *http.Request) {
 	writeResponse(ctx, w, playlist, http.StatusOK)
 }
 
+// DownloadPlaylist exports the playlist in the requested format, defaulting to launcher JSON
 func (a *App) DownloadPlaylist(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	params := mux.Vars(r)
@@ -144,38 +159,65 @@ func (a *App) DownloadPlaylist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	playlist, err := a.Service.GetDownloadablePlaylist(ctx, id)
-	if err != nil {
-		utils.LogCtx(ctx).Error(err)
-		writeError(ctx, w, perr("failed to get playlist", http.StatusInternalServerError))
-		return
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = PlaylistFormatLauncher
 	}
-
-	if playlist == nil {
-		writeError(ctx, w, perr("playlist not found", http.StatusNotFound))
+	format, ok := playlistExportFormats[formatName]
+	if !ok {
+		writeError(ctx, w, perr("format must be one of json, csv, markdown or ids", http.StatusBadRequest))
 		return
 	}
 
-	launcherPlaylist := &types.LauncherPlaylist{
-		ID:          fmt.Sprintf("fpcommunity-%d", playlist.ID),
-		Title:       playlist.Name,
-		Author:      playlist.Author.Username,
-		Description: playlist.Description,
-		Library:     playlist.Library,
-		Icon:        playlist.Icon,
-		Extreme:     !playlist.Public,
-		Games:       playlist.Games,
+	var name string
+	var data []byte
+	if format.NeedsGames {
+		var playlist *types.FullPlaylist
+		playlist, err = a.Service.GetExportablePlaylist(ctx, utils.UserID(ctx), includeExtreme(r), id, a.Fpfss)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			writeError(ctx, w, perr("failed to get playlist", http.StatusInternalServerError))
+			return
+		}
+		if playlist == nil {
+			writeError(ctx, w, perr("playlist not found", http.StatusNotFound))
+			return
+		}
+		name = playlist.Name
+		if formatName == PlaylistFormatCSV {
+			data, err = exportPlaylistCSV(playlist)
+		} else {
+			data = exportPlaylistMarkdown(playlist)
+		}
+	} else {
+		var playlist *types.Playlist
+		playlist, err = a.Service.GetDownloadablePlaylist(ctx, utils.UserID(ctx), id)
+		if err != nil {
+			utils.LogCtx(ctx).Error(err)
+			writeError(ctx, w, perr("failed to get playlist", http.StatusInternalServerError))
+			return
+		}
+		if playlist == nil {
+			writeError(ctx, w, perr("playlist not found", http.StatusNotFound))
+			return
+		}
+		name = playlist.Name
+		if formatName == PlaylistFormatLauncher {
+			data, err = exportLauncherPlaylist(playlist)
+		} else {
+			data = exportPlaylistIDs(playlist)
+		}
 	}
-	data, err := json.Marshal(launcherPlaylist)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
-		writeError(ctx, w, perr("failed to marshal playlist", http.StatusInternalServerError))
+		writeError(ctx, w, perr("failed to export playlist", http.StatusInternalServerError))
 		return
 	}
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", playlist.Name))
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data)
+
+	w.Header().Set("Content-Disposition", contentDisposition(sanitizeFilename(name)+"."+format.Extension))
+	w.Header().Set("Content-Type", format.ContentType)
 	w.WriteHeader(http.StatusOK)
+	w.Write(data)
 }
 
 func (a *App) SubmitPlaylist(w http.ResponseWriter, r *http.Request) {
@@ -229,6 +271,50 @@ func (a *App) SubmitPlaylist(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(parsedPlaylist)
 }
 
+// ImportPlaylist accepts a launcher playlist file, either as the "file" field of a multipart upload or as the raw body
+func (a *App) ImportPlaylist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	r.Body = http.MaxBytesReader(w, r.Body, maxPlaylistImportSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(ctx, w, perr("failed to read uploaded file - "+err.Error(), http.StatusBadRequest))
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	var playlist types.LauncherPlaylist
+	err := json.NewDecoder(body).Decode(&playlist)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode playlist file - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	// Validate fields
+	if strings.TrimSpace(playlist.Title) == "" {
+		writeError(ctx, w, perr("title is a required playlist field", http.StatusBadRequest))
+		return
+	}
+	if len(playlist.Games) == 0 {
+		writeError(ctx, w, perr("playlist must contain at least one game", http.StatusBadRequest))
+		return
+	}
+
+	result, err := a.Service.ImportPlaylist(ctx, uid, &playlist, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, result, http.StatusCreated)
+}
+
 func (a *App) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	uid := utils.UserID(ctx)
@@ -278,7 +364,8 @@ func (a *App) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 
 	newPlaylist, err := a.Service.UpdatePlaylist(ctx, uid, parsedPlaylist, a.Fpfss)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
 		return
 	}
 
@@ -288,6 +375,119 @@ func (a *App) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(newPlaylist)
 }
 
+// PatchPlaylistGames inserts, moves, removes or reorders games without resending the whole playlist
+func (a *App) PatchPlaylistGames(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	var patch types.PlaylistGamesPatch
+	err = json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+	if len(patch.Operations) == 0 {
+		writeError(ctx, w, perr("operations must contain at least one operation", http.StatusBadRequest))
+		return
+	}
+
+	playlist, err := a.Service.PatchPlaylistGames(ctx, uid, id, patch.Operations, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, playlist, http.StatusOK)
+}
+
+// ForkPlaylist copies a public playlist into the user's account as a private draft
+func (a *App) ForkPlaylist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	playlist, err := a.Service.ForkPlaylist(ctx, uid, id, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, playlist, http.StatusCreated)
+}
+
+// LikePlaylist adds or, for DELETE requests, removes the user's like
+func (a *App) LikePlaylist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	var reactions *types.PlaylistReactions
+	if r.Method == http.MethodDelete {
+		reactions, err = a.Service.UnlikePlaylist(ctx, uid, id)
+	} else {
+		reactions, err = a.Service.LikePlaylist(ctx, uid, id)
+	}
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, reactions, http.StatusOK)
+}
+
+// BookmarkPlaylist adds or, for DELETE requests, removes the user's bookmark
+func (a *App) BookmarkPlaylist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	var reactions *types.PlaylistReactions
+	if r.Method == http.MethodDelete {
+		reactions, err = a.Service.UnbookmarkPlaylist(ctx, uid, id)
+	} else {
+		reactions, err = a.Service.BookmarkPlaylist(ctx, uid, id)
+	}
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, reactions, http.StatusOK)
+}
+
 func (a *App) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	uid := utils.UserID(ctx)
@@ -302,7 +502,8 @@ func (a *App) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
 
 	err = a.Service.DeletePlaylist(ctx, uid, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
 		return
 	}
 
@@ -488,7 +689,7 @@ func (a *App) SubmitContentReport(w http.ResponseWriter, r *http.Request) {
 			writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
 			return
 		}
-		playlist, err := a.Service.GetPlaylist(ctx, int64(playlistId), a.Fpfss)
+		playlist, err := a.Service.GetPlaylist(ctx, uid, true, int64(playlistId), a.Fpfss)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -539,10 +740,10 @@ func (a *App) GetGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	game, err := a.Service.GetGame(ctx, id, a.Fpfss)
+	game, err := a.Service.GetGame(ctx, utils.UserID(ctx), includeExtreme(r), id, a.Fpfss)
 	if err != nil {
 		utils.LogCtx(ctx).Error(err)
-		writeError(ctx, w, perr("failed to get game", http.StatusInternalServerError))
+		writeError(ctx, w, err)
 		return
 	}
 
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+)
+
+func (a *App) GetPlaylistCollaborators(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	collaborators, err := a.Service.GetPlaylistCollaborators(ctx, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.PlaylistCollaboratorsResponse{Collaborators: collaborators}, http.StatusOK)
+}
+
+// InvitePlaylistCollaborator invites a user to the playlist, or changes the role of an existing collaborator
+func (a *App) InvitePlaylistCollaborator(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	var sub types.SubmittedPlaylistCollaborator
+	err = json.NewDecoder(r.Body).Decode(&sub)
+	if err != nil {
+		writeError(ctx, w, perr("failed to decode request body - "+err.Error(), http.StatusBadRequest))
+		return
+	}
+	sub.UserID = strings.TrimSpace(sub.UserID)
+	if sub.UserID == "" {
+		writeError(ctx, w, perr("uid is a required field", http.StatusBadRequest))
+		return
+	}
+	if sub.Role != types.PlaylistRoleEditor && sub.Role != types.PlaylistRoleViewer {
+		writeError(ctx, w, perr("role must be editor or viewer", http.StatusBadRequest))
+		return
+	}
+
+	collaborator, err := a.Service.InvitePlaylistCollaborator(ctx, uid, id, &sub)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, collaborator, http.StatusOK)
+}
+
+func (a *App) AcceptPlaylistInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	collaborator, err := a.Service.AcceptPlaylistInvite(ctx, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, collaborator, http.StatusOK)
+}
+
+// RemovePlaylistCollaborator removes a collaborator or declines / withdraws an invite
+func (a *App) RemovePlaylistCollaborator(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+	collaboratorID := params[constants.ResourceKeyUserID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	err = a.Service.RemovePlaylistCollaborator(ctx, uid, id, collaboratorID)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+func (a *App) GetPlaylistInvites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	invites, err := a.Service.GetPlaylistInvites(ctx, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.PlaylistCollaboratorsResponse{Collaborators: invites}, http.StatusOK)
+}
@@ -0,0 +1,188 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/FlashpointProject/CommunityWebsite/types"
+)
+
+const (
+	PlaylistFormatLauncher = "json"
+	PlaylistFormatCSV      = "csv"
+	PlaylistFormatMarkdown = "markdown"
+	PlaylistFormatIDs      = "ids"
+)
+
+// maxExportFilenameLength is in runes, before the extension
+const maxExportFilenameLength = 100
+
+type playlistExportFormat struct {
+	Extension   string
+	ContentType string
+	// NeedsGames is set when the format includes game metadata rather than just IDs and notes
+	NeedsGames bool
+}
+
+var playlistExportFormats = map[string]playlistExportFormat{
+	PlaylistFormatLauncher: {Extension: "json", ContentType: "application/json"},
+	PlaylistFormatCSV:      {Extension: "csv", ContentType: "text/csv; charset=utf-8", NeedsGames: true},
+	PlaylistFormatMarkdown: {Extension: "md", ContentType: "text/markdown; charset=utf-8", NeedsGames: true},
+	PlaylistFormatIDs:      {Extension: "txt", ContentType: "text/plain; charset=utf-8"},
+}
+
+func exportLauncherPlaylist(playlist *types.Playlist) ([]byte, error) {
+	launcherPlaylist := &types.LauncherPlaylist{
+		ID:          fmt.Sprintf("fpcommunity-%d", playlist.ID),
+		Title:       playlist.Name,
+		Author:      playlist.Author.Username,
+		Description: playlist.Description,
+		Library:     playlist.Library,
+		Icon:        playlist.Icon,
+		Extreme:     playlist.Extreme,
+		Games:       playlist.Games,
+	}
+	return json.Marshal(launcherPlaylist)
+}
+
+func exportPlaylistIDs(playlist *types.Playlist) []byte {
+	var buf bytes.Buffer
+	for _, game := range playlist.Games {
+		buf.WriteString(game.GameID)
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func exportPlaylistCSV(playlist *types.FullPlaylist) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err := writer.Write([]string{"game_id", "title", "series", "developer", "publisher", "platform", "release_date", "play_mode", "language", "notes"})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range playlist.Games {
+		game := entry.Game
+		if game == nil {
+			game = &types.CachedGame{}
+		}
+		err = writer.Write([]string{
+			csvCell(entry.GameID),
+			csvCell(game.Title),
+			csvCell(game.Series),
+			csvCell(game.Developer),
+			csvCell(game.Publisher),
+			csvCell(game.Platform),
+			csvCell(game.ReleaseDate),
+			csvCell(strings.Join(game.PlayMode, "; ")),
+			csvCell(strings.Join(game.Language, "; ")),
+			csvCell(entry.Notes),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// csvCell stops spreadsheets from running a value as a formula, by prefixing a quote to anything starting with
+// a character they treat as one
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func exportPlaylistMarkdown(playlist *types.FullPlaylist) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "## %s\n\n", markdownCell(playlist.Name))
+	if playlist.Description != "" {
+		fmt.Fprintf(&buf, "%s\n\n", markdownEscape(strings.ReplaceAll(playlist.Description, "\r\n", "\n")))
+	}
+	buf.WriteString("| # | Title | Developer | Platform | Notes |\n")
+	buf.WriteString("|---|-------|-----------|----------|-------|\n")
+	for i, entry := range playlist.Games {
+		title := entry.GameID
+		developer, platform := "", ""
+		if entry.Game != nil && !entry.Game.Missing {
+			title = entry.Game.Title
+			developer = entry.Game.Developer
+			platform = entry.Game.Platform
+		}
+		fmt.Fprintf(&buf, "| %d | %s | %s | %s | %s |\n", i+1, markdownCell(title), markdownCell(developer), markdownCell(platform), markdownCell(entry.Notes))
+	}
+	return buf.Bytes()
+}
+
+// markdownCell keeps a value on one line and stops it from breaking out of the table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return markdownEscape(strings.TrimSpace(s))
+}
+
+// markdownSpecial backslash-escapes everything Markdown could read as formatting, links or inline HTML
+var markdownSpecial = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "{", "\\{", "}", "\\}", "[", "\\[", "]", "\\]",
+	"(", "\\(", ")", "\\)", "#", "\\#", "+", "\\+", "-", "\\-", ".", "\\.", "!", "\\!", "|", "\\|",
+	"<", "\\<", ">", "\\>", "~", "\\~", "&", "\\&", "=", "\\=",
+)
+
+// markdownEscape makes user supplied text render literally, line breaks are kept
+func markdownEscape(s string) string {
+	return markdownSpecial.Replace(s)
+}
+
+// sanitizeFilename drops path separators, reserved and control characters, falling back to "playlist" if nothing is left
+func sanitizeFilename(name string) string {
+	var b strings.Builder
+	count := 0
+	for _, r := range name {
+		if count >= maxExportFilenameLength {
+			break
+		}
+		switch {
+		case unicode.IsControl(r), strings.ContainsRune(`<>:"/\|?*`, r):
+			b.WriteRune('_')
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r)
+		}
+		count++
+	}
+	res := strings.Trim(b.String(), " ._")
+	if res == "" {
+		return "playlist"
+	}
+	return res
+}
+
+// contentDisposition builds an RFC 6266 attachment header with an ASCII fallback and a UTF-8 filename* parameter
+func contentDisposition(filename string) string {
+	var fallback strings.Builder
+	var encoded strings.Builder
+	for _, r := range filename {
+		if r < utf8.RuneSelf && r >= 0x20 && r != '"' && r != '\\' && r != 0x7f {
+			fallback.WriteRune(r)
+		} else {
+			fallback.WriteRune('_')
+		}
+	}
+	for _, c := range []byte(filename) {
+		// RFC 5987 attr-char
+		if c < utf8.RuneSelf && (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", fallback.String(), encoded.String())
+}
@@ -0,0 +1,100 @@
+package transport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+)
+
+func (a *App) GetPlaylistRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	revisions, err := a.Service.GetPlaylistRevisions(ctx, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.PlaylistRevisionsResponse{Revisions: revisions}, http.StatusOK)
+}
+
+func (a *App) DiffPlaylistRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeyPlaylistID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		writeError(ctx, w, perr("failed to parse form", http.StatusBadRequest))
+		return
+	}
+
+	var query types.PlaylistRevisionDiffQuery
+	err = schema.NewDecoder().Decode(&query, r.Form)
+	if err != nil {
+		writeError(ctx, w, perr(fmt.Sprintf("failed to decode form: %s", err.Error()), http.StatusBadRequest))
+		return
+	}
+	if query.From <= 0 || query.To <= 0 {
+		writeError(ctx, w, perr("from and to are required revisions", http.StatusBadRequest))
+		return
+	}
+
+	diff, err := a.Service.DiffPlaylistRevisions(ctx, uid, id, query.From, query.To)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, diff, http.StatusOK)
+}
+
+func (a *App) RestorePlaylistRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+
+	id, err := strconv.ParseInt(params[constants.ResourceKeyPlaylistID], 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid playlist id", http.StatusBadRequest))
+		return
+	}
+	revision, err := strconv.ParseInt(params[constants.ResourceKeyRevision], 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid revision", http.StatusBadRequest))
+		return
+	}
+
+	playlist, err := a.Service.RestorePlaylistRevision(ctx, uid, id, revision, a.Fpfss)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, playlist, http.StatusOK)
+}
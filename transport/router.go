@@ -33,58 +33,214 @@ func (a *App) ServeRouter(l *logrus.Entry, srv *http.Server, router *mux.Router)
 		http.HandlerFunc(a.RequestData(a.OAuthLogin))).
 		Methods("GET")
 
+	router.Handle("/auth/logout",
+		http.HandlerFunc(a.RequestJSON(a.Logout))).
+		Methods("POST")
+
 	// Profile
 
 	router.Handle("/api/profile",
-		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.GetProfile)))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeProfileRead, a.UserAuthMux(a.GetProfile))))).
+		Methods("GET")
+
+	router.Handle("/api/profile/filters",
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeProfileRead, a.UserAuthMux(a.GetFilterPreferences))))).
+		Methods("GET")
+
+	router.Handle("/api/profile/filters",
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.SaveFilterPreferences)))).
+		Methods("PUT")
+
+	router.Handle("/api/profile/bookmarks",
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeProfileRead, a.UserAuthMux(a.GetBookmarkedPlaylists))))).
 		Methods("GET")
 
+	router.Handle("/api/profile/playlist-invites",
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeProfileRead, a.UserAuthMux(a.GetPlaylistInvites))))).
+		Methods("GET")
+
+	router.Handle("/api/profile/sessions",
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.GetSessions)))).
+		Methods("GET")
+
+	router.Handle("/api/profile/sessions",
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.RevokeSessions)))).
+		Methods("DELETE")
+
+	router.Handle(fmt.Sprintf("/api/profile/session/{%s}", constants.ResourceKeySessionID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.RevokeSession)))).
+		Methods("DELETE")
+
+	router.Handle("/api/profile/tokens",
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.GetApiTokens)))).
+		Methods("GET")
+
+	router.Handle("/api/profile/tokens",
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.CreateApiToken)))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/profile/token/{%s}", constants.ResourceKeyApiTokenID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.RevokeApiToken)))).
+		Methods("DELETE")
+
 	router.Handle(fmt.Sprintf("/api/profile/{%s}", constants.ResourceKeyUserID),
-		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.GetUserProfile)))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeProfileRead, a.UserAuthMux(a.GetUserProfile))))).
 		Methods("GET")
 
+	router.Handle(fmt.Sprintf("/api/profile/{%s}/sessions", constants.ResourceKeyUserID),
+		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.RevokeUserSessions, isStaff)))).
+		Methods("DELETE")
+
 	// Playlist
 
 	router.Handle("/api/playlists",
-		http.HandlerFunc(a.RequestJSON(a.SearchPlaylists))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.OptionalAuthMux(a.SearchPlaylists))))).
 		Methods("GET")
 
 	router.Handle("/api/playlists",
-		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.SubmitPlaylist)))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.SubmitPlaylist))))).
+		Methods("POST")
+
+	router.Handle("/api/playlists/import",
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.ImportPlaylist))))).
 		Methods("POST")
 
 	router.Handle(fmt.Sprintf("/api/playlist/{%s}", constants.ResourceKeyPlaylistID),
-		http.HandlerFunc(a.RequestJSON(a.GetPlaylist))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.OptionalAuthMux(a.GetPlaylist))))).
 		Methods("GET")
 
 	router.Handle(fmt.Sprintf("/api/playlist/{%s}/preview", constants.ResourceKeyPlaylistID),
-		http.HandlerFunc(a.RequestJSON(a.GetPlaylistPreview))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.OptionalAuthMux(a.GetPlaylistPreview))))).
 		Methods("GET")
 
 	router.Handle(fmt.Sprintf("/api/playlist/{%s}/download", constants.ResourceKeyPlaylistID),
-		http.HandlerFunc(a.RequestJSON(a.DownloadPlaylist))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.OptionalAuthMux(a.DownloadPlaylist))))).
 		Methods("GET")
 
 	router.Handle(fmt.Sprintf("/api/playlist/{%s}", constants.ResourceKeyPlaylistID),
-		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.UpdatePlaylist)))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.UpdatePlaylist))))).
 		Methods("PUT", "POST")
 
 	router.Handle(fmt.Sprintf("/api/playlist/{%s}", constants.ResourceKeyPlaylistID),
-		http.HandlerFunc(a.RequestJSON(a.UserAuthMux(a.DeletePlaylist)))).
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.DeletePlaylist))))).
+		Methods("DELETE")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/games", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.PatchPlaylistGames))))).
+		Methods("PATCH")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/fork", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.ForkPlaylist))))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/like", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.LikePlaylist))))).
+		Methods("PUT", "DELETE")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/bookmark", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.BookmarkPlaylist))))).
+		Methods("PUT", "DELETE")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/revisions", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.UserAuthMux(a.GetPlaylistRevisions))))).
+		Methods("GET")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/revisions/diff", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.UserAuthMux(a.DiffPlaylistRevisions))))).
+		Methods("GET")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/revision/{%s}/restore", constants.ResourceKeyPlaylistID, constants.ResourceKeyRevision),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.RestorePlaylistRevision))))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/collaborators", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsRead, a.UserAuthMux(a.GetPlaylistCollaborators))))).
+		Methods("GET")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/collaborators", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.InvitePlaylistCollaborator))))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/collaborators/accept", constants.ResourceKeyPlaylistID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.AcceptPlaylistInvite))))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/playlist/{%s}/collaborator/{%s}", constants.ResourceKeyPlaylistID, constants.ResourceKeyUserID),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopePlaylistsWrite, a.UserAuthMux(a.RemovePlaylistCollaborator))))).
 		Methods("DELETE")
 
 	// Games
 
+	router.Handle("/api/games",
+		http.HandlerFunc(a.RequestJSON(a.OptionalAuthMux(a.SearchGames)))).
+		Methods("GET")
+
 	router.Handle(fmt.Sprintf("/api/game/{%s}", constants.ResourceKeyGameID),
-		http.HandlerFunc(a.RequestJSON(a.GetGame))).
+		http.HandlerFunc(a.RequestJSON(a.OptionalAuthMux(a.GetGame)))).
+		Methods("GET")
+
+	router.Handle(fmt.Sprintf("/api/game/{%s}/community", constants.ResourceKeyGameID),
+		http.HandlerFunc(a.RequestJSON(a.OptionalAuthMux(a.GetGameCommunityPage)))).
 		Methods("GET")
 
 	// GOTD
 
 	router.Handle("/api/gotd/suggestions",
-		http.HandlerFunc(a.RequestJSON(a.SearchGotdSuggestions))).
+		http.HandlerFunc(a.RequestJSON(a.OptionalAuthMux(a.SearchGotdSuggestions)))).
 		Methods("GET")
 
+	router.Handle("/api/gotd/suggestions",
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeGotdSuggest, a.UserAuthMux(a.SubmitGotdSuggestion))))).
+		Methods("POST")
+
+	router.Handle(fmt.Sprintf("/api/gotd/suggestion/{%s}", constants.ResourceKeySuggestion),
+		http.HandlerFunc(a.RequestJSON(a.TokenScope(constants.ScopeGotdSuggest, a.UserAuthMux(a.DeleteGotdSuggestion))))).
+		Methods("DELETE")
+
+	router.Handle("/api/gotd.json",
+		http.HandlerFunc(a.RequestData(a.GetGotdFile))).
+		Methods("GET", "HEAD")
+
+	router.Handle("/api/gotd",
+		http.HandlerFunc(a.RequestJSON(a.GetGotdCurrent))).
+		Methods("GET")
+
+	f := a.UserAuthMux(a.GetGotdSchedule, isStaff)
+
+	router.Handle("/api/gotd/schedule",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("GET")
+
+	f = a.UserAuthMux(a.AssignGotd, isStaff)
+
+	router.Handle("/api/gotd/schedule",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("POST")
+
+	f = a.UserAuthMux(a.PreviewGotdSchedule, isStaff)
+
+	router.Handle("/api/gotd/schedule/auto",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("GET")
+
+	f = a.UserAuthMux(a.ApplyGotdSchedule, isStaff)
+
+	router.Handle("/api/gotd/schedule/auto",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("POST")
+
+	f = a.UserAuthMux(a.ImportGotdFile, isStaff)
+
+	router.Handle("/api/gotd/import",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("POST")
+
+	f = a.UserAuthMux(a.UnassignGotd, isStaff)
+
+	router.Handle(fmt.Sprintf("/api/gotd/schedule/{%s}", constants.ResourceKeyGotdDate),
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("DELETE")
+
 	// News
 
 	router.Handle(fmt.Sprintf("/api/post/{%s}", constants.ResourceKeyPostID),
@@ -95,7 +251,7 @@ func (a *App) ServeRouter(l *logrus.Entry, srv *http.Server, router *mux.Router)
 		http.HandlerFunc(a.RequestJSON(a.SearchNewsPosts))).
 		Methods("GET")
 
-	f := a.UserAuthMux(a.SubmitNewsPost, isStaff)
+	f = a.UserAuthMux(a.SubmitNewsPost, isStaff)
 
 	router.Handle("/api/posts",
 		http.HandlerFunc(a.RequestJSON(f))).
@@ -115,6 +271,14 @@ func (a *App) ServeRouter(l *logrus.Entry, srv *http.Server, router *mux.Router)
 		http.HandlerFunc(a.RequestJSON(f))).
 		Methods("POST")
 
+	// FPFSS
+
+	f = a.UserAuthMux(a.GetFpfssMetrics, isStaff)
+
+	router.Handle("/api/fpfss/metrics",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("GET")
+
 	// Roles
 
 	router.Handle("/api/roles",
@@ -127,6 +291,24 @@ func (a *App) ServeRouter(l *logrus.Entry, srv *http.Server, router *mux.Router)
 		http.HandlerFunc(a.RequestJSON(a.GetFilterGroups))).
 		Methods("GET")
 
+	f = a.UserAuthMux(a.CreateFilterGroup, isStaff)
+
+	router.Handle("/api/filter-groups",
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("POST")
+
+	f = a.UserAuthMux(a.UpdateFilterGroup, isStaff)
+
+	router.Handle(fmt.Sprintf("/api/filter-group/{%s}", constants.ResourceKeyFilterGroup),
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("PUT")
+
+	f = a.UserAuthMux(a.DeleteFilterGroup, isStaff)
+
+	router.Handle(fmt.Sprintf("/api/filter-group/{%s}", constants.ResourceKeyFilterGroup),
+		http.HandlerFunc(a.RequestJSON(f))).
+		Methods("DELETE")
+
 	// Single Page Application (SPA)
 
 	mime.AddExtensionType(".js", "application/javascript") // Windows being weird?
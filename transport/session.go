@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/FlashpointProject/CommunityWebsite/constants"
+	"github.com/FlashpointProject/CommunityWebsite/types"
+	"github.com/FlashpointProject/CommunityWebsite/utils"
+	"github.com/gorilla/mux"
+)
+
+func (a *App) GetSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	sessions, err := a.Service.GetUserSessions(ctx, uid, utils.SessionID(ctx))
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	writeResponse(ctx, w, &types.SessionsResponse{Sessions: sessions}, http.StatusOK)
+}
+
+func (a *App) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+	params := mux.Vars(r)
+	idStr := params[constants.ResourceKeySessionID]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		writeError(ctx, w, perr("invalid session id", http.StatusBadRequest))
+		return
+	}
+
+	err = a.Service.RevokeSession(ctx, uid, id)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	if id == utils.SessionID(ctx) {
+		unsetLoginCookies(w)
+	}
+
+	writeResponse(ctx, w, nil, http.StatusOK)
+}
+
+// RevokeSessions logs the user out of every session, including the current one
+func (a *App) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	uid := utils.UserID(ctx)
+
+	revoked, err := a.Service.RevokeUserSessions(ctx, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	unsetLoginCookies(w)
+	writeResponse(ctx, w, &types.RevokedSessionsResponse{Revoked: revoked}, http.StatusOK)
+}
+
+// RevokeUserSessions lets staff log a user, such as a banned one, out everywhere and revoke their API tokens
+func (a *App) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := mux.Vars(r)
+	uid := params[constants.ResourceKeyUserID]
+
+	revoked, revokedTokens, err := a.Service.RevokeUserAccess(ctx, uid)
+	if err != nil {
+		utils.LogCtx(ctx).Error(err)
+		writeError(ctx, w, err)
+		return
+	}
+
+	utils.LogCtx(ctx).Infof("revoked %d sessions and %d API tokens of user %s", revoked, revokedTokens, uid)
+	writeResponse(ctx, w, &types.RevokedSessionsResponse{Revoked: revoked, RevokedTokens: revokedTokens}, http.StatusOK)
+}
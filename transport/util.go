@@ -42,3 +42,9 @@ func HasAnyRole(has, needs []string) bool {
 	}
 	return false
 }
+
+// includeExtreme reports whether the request explicitly opted in to extreme content with ?extreme=true.
+// Only honoured for logged in viewers, see Service.contentFilter
+func includeExtreme(r *http.Request) bool {
+	return r.URL.Query().Get("extreme") == "true"
+}
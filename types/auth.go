@@ -11,8 +11,61 @@ type AuthTokenResponse struct {
 type SessionInfo struct {
 	ID        int64     `json:"id"`
 	UID       string    `json:"uid"`
+	CreatedAt time.Time `json:"created_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	IpAddr    string    `json:"ip_addr"`
+	UserAgent string    `json:"user_agent"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+	// RotationRequired is set when the user's roles changed after the session's secret was issued
+	RotationRequired bool     `json:"-"`
+	Roles            []string `json:"-"`
+	// TokenID and Scopes are set instead of ID when the request authenticated with a personal API token
+	TokenID int64    `json:"-"`
+	Scopes  []string `json:"-"`
+}
+
+type ApiToken struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// SubmittedApiToken creates a token that never expires when ExpiresAt is omitted
+type SubmittedApiToken struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedApiToken carries the token's secret, which is only ever shown in this response
+type CreatedApiToken struct {
+	Token  *ApiToken `json:"token"`
+	Secret string    `json:"secret"`
+}
+
+type ApiTokensResponse struct {
+	Tokens []*ApiToken `json:"tokens"`
+}
+
+type SessionsResponse struct {
+	Sessions []*SessionInfo `json:"sessions"`
+}
+
+type RevokedSessionsResponse struct {
+	Revoked       int64 `json:"revoked"`
+	RevokedTokens int64 `json:"revoked_tokens"`
+}
+
+// OAuthState is a pending login, looked up by the nonce sent as the OAuth state parameter
+type OAuthState struct {
+	Nonce        string
+	RedirectURI  string
+	CodeVerifier string
+	ExpiresAt    time.Time
 }
 
 type FPFSSProfile struct {
@@ -32,11 +85,12 @@ type UserProfile struct {
 }
 
 type Cookies struct {
-	Login     string
-	UserID    string
-	Username  string
-	AvatarURL string
-	Roles     string
+	Login      string
+	UserID     string
+	Username   string
+	AvatarURL  string
+	Roles      string
+	OAuthState string
 }
 
 type ClientCredentialsRequest struct {
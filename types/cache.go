@@ -18,6 +18,7 @@ type CachedGame struct {
 	Tags                []*CachedTag `json:"tags"`
 	UpdatedAt           time.Time    `json:"updated_at"`
 	Missing             bool         `json:"missing"`
+	Removed             bool         `json:"removed"`
 }
 
 type CachedTag struct {
@@ -0,0 +1,62 @@
+package types
+
+import "time"
+
+type FilterGroup struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	Extreme   bool      `json:"extreme"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ResponseFilterGroups struct {
+	Groups []*FilterGroup `json:"groups"`
+}
+
+type SubmittedFilterGroup struct {
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Extreme bool     `json:"extreme"`
+}
+
+// FilterGroupRecomputeResult reports how many cached rows changed after a filter group edit
+type FilterGroupRecomputeResult struct {
+	Group     *FilterGroup `json:"group"`
+	Games     int64        `json:"games_updated"`
+	Playlists int64        `json:"playlists_updated"`
+}
+
+// ContentFilter is what a viewer has hidden, resolved to filter group names
+type ContentFilter struct {
+	HiddenFilterGroups []string
+	HideExtreme        bool
+}
+
+// Hides reports whether the game falls under any hidden group
+func (f *ContentFilter) Hides(game *CachedGame) bool {
+	if f == nil || game == nil {
+		return false
+	}
+	if f.HideExtreme && game.Extreme {
+		return true
+	}
+	for _, group := range game.FilterGroups {
+		for _, hidden := range f.HiddenFilterGroups {
+			if group == hidden {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type UserFilterPreferences struct {
+	HiddenFilterGroups []int64 `json:"hidden_filter_groups"`
+	Default            bool    `json:"default"`
+}
+
+type SubmittedFilterPreferences struct {
+	HiddenFilterGroups []int64 `json:"hidden_filter_groups"`
+}
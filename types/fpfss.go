@@ -1,5 +1,7 @@
 package types
 
+import "context"
+
 type FpfssGame struct {
 	ID                  string      `json:"id"`
 	Title               string      `json:"title"`
@@ -25,7 +27,27 @@ type ResponseFpfssGamesFetch struct {
 	Games []*FpfssGame `json:"games"`
 }
 
+// FpfssDump is the on-disk format read by the file-backed FPFSS source
+type FpfssDump struct {
+	Games []*FpfssGame             `json:"games"`
+	Users []*FlashpointDiscordUser `json:"users"`
+}
+
+// FpfssGameSearch matches games whose fields contain each non-empty text, platform and tag must match exactly
+type FpfssGameSearch struct {
+	Title     string `json:"title"`
+	Developer string `json:"developer"`
+	Publisher string `json:"publisher"`
+	Series    string `json:"series"`
+	Platform  string `json:"platform"`
+	Tag       string `json:"tag"`
+	Limit     int64  `json:"limit"`
+}
+
 type IFpfss interface {
-	GetGame(id string) (*FpfssGame, error)
-	GetGames(ids []string) ([]*FpfssGame, error)
+	GetGame(ctx context.Context, id string) (*FpfssGame, error)
+	GetGames(ctx context.Context, ids []string) ([]*FpfssGame, error)
+	SearchGames(ctx context.Context, search *FpfssGameSearch) ([]*FpfssGame, error)
+	// GetUserRoles returns nil if the user is not a member of the Discord server
+	GetUserRoles(ctx context.Context, uid string) (*FlashpointDiscordUser, error)
 }
@@ -0,0 +1,54 @@
+package types
+
+// GamePlaylistEntry is a public playlist containing the game, with the curator's notes on it
+type GamePlaylistEntry struct {
+	Playlist *PlaylistInfo `json:"playlist"`
+	Notes    string        `json:"notes"`
+	Position int           `json:"position"`
+}
+
+// RelatedGame is a game that shares public playlists with another
+type RelatedGame struct {
+	Game            *CachedGame `json:"game"`
+	SharedPlaylists int64       `json:"shared_playlists"`
+}
+
+type GameCommunityPage struct {
+	Game           *CachedGame          `json:"game"`
+	Playlists      []*GamePlaylistEntry `json:"playlists"`
+	TotalPlaylists int64                `json:"total_playlists"`
+	GotdHistory    []*GotdGame          `json:"gotd_history"`
+	Suggestions    []*GotdSuggestion    `json:"suggestions"`
+	RelatedGames   []*RelatedGame       `json:"related_games"`
+}
+
+type GameSearchQuery struct {
+	Title       string `json:"title" schema:"title"`
+	Developer   string `json:"developer" schema:"developer"`
+	Publisher   string `json:"publisher" schema:"publisher"`
+	Series      string `json:"series" schema:"series"`
+	Platform    string `json:"platform" schema:"platform"`
+	Tag         string `json:"tag" schema:"tag"`
+	FilterGroup string `json:"filter_group" schema:"filter_group"`
+	// Autocomplete matches title as typed so far, by prefix or trigram similarity, best matches first
+	Autocomplete bool `json:"autocomplete" schema:"autocomplete"`
+	// Fpfss searches FPFSS when nothing in the local cache matches, logged in users only
+	Fpfss        bool  `json:"fpfss" schema:"fpfss"`
+	Extreme      bool  `json:"extreme" schema:"extreme"`
+	Page         int64 `json:"page" schema:"page"`
+	PageSize     int64 `json:"page_size" schema:"page_size"`
+	IncludeTotal bool  `json:"include_total" schema:"include_total"`
+	// Filter is resolved from the viewer's preferences rather than the request
+	Filter *ContentFilter `json:"-" schema:"-"`
+}
+
+const (
+	GameSearchSourceCache = "cache"
+	GameSearchSourceFpfss = "fpfss"
+)
+
+type GameSearchResponse struct {
+	Games  []*CachedGame `json:"games"`
+	Total  int64         `json:"total"`
+	Source string        `json:"source"`
+}
@@ -7,6 +7,7 @@ type GotdGame struct {
 	Author       string    `json:"author"`
 	Description  string    `json:"description"`
 	AssignedDate time.Time `json:"date"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type GotdFileGame struct {
@@ -20,6 +21,20 @@ type GotdFile struct {
 	Games []GotdFileGame `json:"games"`
 }
 
+type GotdImportIssue struct {
+	GameID string `json:"game_id"`
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+type GotdImportResult struct {
+	Imported     int                `json:"imported"`
+	Updated      int                `json:"updated"`
+	Conflicts    []*GotdImportIssue `json:"conflicts"`
+	UnknownGames []*GotdImportIssue `json:"unknown_games"`
+	Invalid      []*GotdImportIssue `json:"invalid"`
+}
+
 type GotdSuggestion struct {
 	ID            int64       `json:"id"`
 	Game          *CachedGame `json:"game"`
@@ -29,6 +44,13 @@ type GotdSuggestion struct {
 	CreatedAt     time.Time   `json:"created_at"`
 }
 
+type SubmittedGotdSuggestion struct {
+	GameID        string `json:"game_id"`
+	Anonymous     bool   `json:"anonymous"`
+	Description   string `json:"description"`
+	SuggestedDate string `json:"suggested_date"`
+}
+
 type GotdSuggestionInternal struct {
 	ID            int64        `json:"id"`
 	Game          *CachedGame  `json:"game"`
@@ -40,15 +62,47 @@ type GotdSuggestionInternal struct {
 }
 
 type GetGotdCurrentQuery struct {
-	ShowFuture bool `json:"show_future"`
+	ShowFuture bool `json:"show_future" schema:"show_future"`
+}
+
+type GotdAssignRequest struct {
+	SuggestionID int64  `json:"suggestion_id"`
+	Date         string `json:"date"`
+}
+
+type GotdScheduleResponse struct {
+	Games []*GotdGame `json:"games"`
+}
+
+type GotdAutoScheduleQuery struct {
+	StartDate string `json:"start_date" schema:"start_date"`
+	Days      int64  `json:"days" schema:"days"`
+	// DeveloperGapDays is the minimum spacing between games by the same developer, negative disables the check
+	DeveloperGapDays int64 `json:"developer_gap_days" schema:"developer_gap_days"`
+	// PlanHash is the preview's plan_hash, applying is refused if the plan no longer matches it
+	PlanHash string `json:"plan_hash" schema:"-"`
+}
+
+type GotdProposal struct {
+	Date       string          `json:"date"`
+	Suggestion *GotdSuggestion `json:"suggestion"`
+}
+
+type GotdAutoScheduleResponse struct {
+	Proposals     []*GotdProposal `json:"proposals"`
+	UnfilledDates []string        `json:"unfilled_dates"`
+	PlanHash      string          `json:"plan_hash"`
 }
 
 type GotdSuggestionsSearchQuery struct {
-	Page           int64  `schema:"page"`
-	PageSize       int64  `schema:"page_size"`
-	OrderBy        string `schema:"order_by"`
-	OrderDirection string `schema:"order_direction"`
-	IncludeTotal   bool   `schema:"include_total"`
+	Page           int64          `schema:"page"`
+	PageSize       int64          `schema:"page_size"`
+	OrderBy        string         `schema:"order_by"`
+	OrderDirection string         `schema:"order_direction"`
+	IncludeTotal   bool           `schema:"include_total"`
+	Extreme        bool           `schema:"extreme"`
+	GameID         string         `schema:"game_id"`
+	Filter         *ContentFilter `schema:"-"`
 }
 
 type GotdSuggestionsSearchResponse struct {
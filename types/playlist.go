@@ -13,6 +13,9 @@ type PlaylistInfo struct {
 	Public       bool         `json:"public"`
 	Extreme      bool         `json:"extreme"`
 	FilterGroups []string     `json:"filter_groups"`
+	ForkedFrom   *int64       `json:"forked_from"`
+	ForkCount    int64        `json:"fork_count"`
+	Likes        int64        `json:"likes"`
 	CreatedAt    time.Time    `json:"created_at"`
 	UpdatedAt    time.Time    `json:"updated_at"`
 }
@@ -29,24 +32,41 @@ type Playlist struct {
 	Public       bool                   `json:"public"`
 	Extreme      bool                   `json:"extreme"`
 	FilterGroups []string               `json:"filter_groups"`
+	ForkedFrom   *int64                 `json:"forked_from"`
+	ForkCount    int64                  `json:"fork_count"`
+	Likes        int64                  `json:"likes"`
 	CreatedAt    time.Time              `json:"created_at"`
 	UpdatedAt    time.Time              `json:"updated_at"`
 }
 
 type FullPlaylist struct {
-	ID           int64           `json:"id"`
-	Name         string          `json:"name"`
-	TotalGames   int             `json:"total_games"`
-	Description  string          `json:"description"`
-	Author       *UserProfile    `json:"author"`
-	Library      string          `json:"library"`
-	Icon         string          `json:"icon"`
-	Games        []GameWithNotes `json:"games"`
-	Public       bool            `json:"public"`
-	Extreme      bool            `json:"extreme"`
-	FilterGroups []string        `json:"filter_groups"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	ID           int64            `json:"id"`
+	Name         string           `json:"name"`
+	TotalGames   int              `json:"total_games"`
+	Description  string           `json:"description"`
+	Author       *UserProfile     `json:"author"`
+	Library      string           `json:"library"`
+	Icon         string           `json:"icon"`
+	Games        []GameWithNotes  `json:"games"`
+	HiddenGames  int              `json:"hidden_games"`
+	Public       bool             `json:"public"`
+	Extreme      bool             `json:"extreme"`
+	FilterGroups []string         `json:"filter_groups"`
+	ForkedFrom   *int64           `json:"forked_from"`
+	ForkCount    int64            `json:"fork_count"`
+	Likes        int64            `json:"likes"`
+	Liked        bool             `json:"liked"`
+	Bookmarked   bool             `json:"bookmarked"`
+	Lineage      *PlaylistLineage `json:"lineage,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// PlaylistLineage links a fork to its source, which is left out if it was deleted or the viewer can't see it,
+// and lists the playlist's most forked public forks
+type PlaylistLineage struct {
+	Source   *PlaylistInfo   `json:"source"`
+	TopForks []*PlaylistInfo `json:"top_forks"`
 }
 
 type GameWithNotes struct {
@@ -71,6 +91,20 @@ type LauncherPlaylist struct {
 	Games       []LauncherPlaylistGame `json:"games"`
 }
 
+type PlaylistImportIssue struct {
+	Index  int    `json:"index"`
+	GameID string `json:"game_id"`
+	Reason string `json:"reason"`
+}
+
+type PlaylistImportResult struct {
+	Playlist     *Playlist              `json:"playlist"`
+	Imported     int                    `json:"imported"`
+	UnknownGames []*PlaylistImportIssue `json:"unknown_games"`
+	Invalid      []*PlaylistImportIssue `json:"invalid"`
+	Duplicates   []*PlaylistImportIssue `json:"duplicates"`
+}
+
 type PlaylistSearchQuery struct {
 	Page           int64  `json:"page" schema:"page"`
 	PageSize       int64  `json:"page_size" schema:"page_size"`
@@ -81,11 +115,37 @@ type PlaylistSearchQuery struct {
 	OrderBy        string `json:"order_by" schema:"order_by"`
 	OrderDirection string `json:"order_direction" schema:"order_direction"`
 	IncludeTotal   bool   `json:"include_total" schema:"include_total"`
+	// Query is a full text search, in web search syntax, over playlist names and descriptions and the titles,
+	// developers and tags of their games. Results are ordered by relevance unless OrderBy is set.
+	Query         string `json:"q" schema:"q"`
+	GameID        string `json:"game_id" schema:"game_id"`
+	Platform      string `json:"platform" schema:"platform"`
+	Tag           string `json:"tag" schema:"tag"`
+	FilterGroup   string `json:"filter_group" schema:"filter_group"`
+	IncludeFacets bool   `json:"include_facets" schema:"include_facets"`
+	// Filter is resolved from the viewer's preferences rather than the request
+	Filter *ContentFilter `json:"-" schema:"-"`
+	// ViewerID also shows private playlists the viewer owns or collaborates on
+	ViewerID string `json:"-" schema:"-"`
+	// BookmarkedBy limits results to the user's bookmarks
+	BookmarkedBy string `json:"-" schema:"-"`
 }
 
 type PlaylistSearchResponse struct {
-	Playlists []*PlaylistInfo `json:"playlists"`
-	Total     int64           `json:"total"`
+	Playlists []*PlaylistInfo       `json:"playlists"`
+	Total     int64                 `json:"total"`
+	Facets    *PlaylistSearchFacets `json:"facets,omitempty"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type PlaylistSearchFacets struct {
+	Library     []*FacetCount `json:"library"`
+	Platform    []*FacetCount `json:"platform"`
+	FilterGroup []*FacetCount `json:"filter_group"`
 }
 
 type NewsPostSearchQuery struct {
@@ -103,3 +163,113 @@ type NewsPostSearchResponse struct {
 	Posts []*NewsPost `json:"posts"`
 	Total int64       `json:"total"`
 }
+
+type PlaylistRevision struct {
+	ID          int64                  `json:"id"`
+	PlaylistID  int64                  `json:"playlist_id"`
+	Revision    int64                  `json:"revision"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Library     string                 `json:"library"`
+	Icon        string                 `json:"icon"`
+	Public      bool                   `json:"public"`
+	Games       []LauncherPlaylistGame `json:"games"`
+	CreatedBy   *UserProfile           `json:"created_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+type PlaylistRevisionInfo struct {
+	Revision   int64        `json:"revision"`
+	Name       string       `json:"name"`
+	TotalGames int          `json:"total_games"`
+	CreatedBy  *UserProfile `json:"created_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type PlaylistRevisionsResponse struct {
+	Revisions []*PlaylistRevisionInfo `json:"revisions"`
+}
+
+type PlaylistRevisionDiffQuery struct {
+	From int64 `schema:"from"`
+	To   int64 `schema:"to"`
+}
+
+type PlaylistFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type PlaylistGameMove struct {
+	GameID       string `json:"game_id"`
+	FromPosition int    `json:"from_position"`
+	ToPosition   int    `json:"to_position"`
+}
+
+type PlaylistNotesChange struct {
+	GameID string `json:"game_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+type PlaylistRevisionDiff struct {
+	From         int64                  `json:"from"`
+	To           int64                  `json:"to"`
+	Fields       []*PlaylistFieldChange `json:"fields"`
+	Added        []LauncherPlaylistGame `json:"added"`
+	Removed      []LauncherPlaylistGame `json:"removed"`
+	Reordered    []*PlaylistGameMove    `json:"reordered"`
+	NotesChanged []*PlaylistNotesChange `json:"notes_changed"`
+}
+
+const (
+	PlaylistGameOpInsert  = "insert"
+	PlaylistGameOpMove    = "move"
+	PlaylistGameOpRemove  = "remove"
+	PlaylistGameOpReorder = "reorder"
+)
+
+// PlaylistGameOperation is one change to a playlist's game list. Index is the position the game ends up at,
+// insert appends when it is omitted. Reorder takes every game ID in the playlist in the new order.
+type PlaylistGameOperation struct {
+	Op      string   `json:"op"`
+	GameID  string   `json:"game_id"`
+	Notes   string   `json:"notes"`
+	Index   *int     `json:"index"`
+	GameIDs []string `json:"game_ids"`
+}
+
+type PlaylistGamesPatch struct {
+	Operations []*PlaylistGameOperation `json:"operations"`
+}
+
+const (
+	PlaylistRoleEditor = "editor"
+	PlaylistRoleViewer = "viewer"
+)
+
+type PlaylistCollaborator struct {
+	PlaylistID int64        `json:"playlist_id"`
+	User       *UserProfile `json:"user"`
+	Role       string       `json:"role"`
+	InvitedBy  string       `json:"invited_by"`
+	AcceptedAt *time.Time   `json:"accepted_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type SubmittedPlaylistCollaborator struct {
+	UserID string `json:"uid"`
+	Role   string `json:"role"`
+}
+
+type PlaylistCollaboratorsResponse struct {
+	Collaborators []*PlaylistCollaborator `json:"collaborators"`
+}
+
+// PlaylistReactions is the playlist's like count and whether the user has liked and bookmarked it
+type PlaylistReactions struct {
+	Likes      int64 `json:"likes"`
+	Liked      bool  `json:"liked"`
+	Bookmarked bool  `json:"bookmarked"`
+}
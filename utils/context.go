@@ -9,22 +9,26 @@ import (
 type contextString string
 
 type ctxKeys struct {
-	UserID      contextString
-	Log         contextString
-	RequestID   contextString
-	RequestType contextString
-	Scope       contextString
-	Fpfss       contextString
+	UserID        contextString
+	SessionID     contextString
+	Log           contextString
+	RequestID     contextString
+	RequestType   contextString
+	Scope         contextString
+	RequiredScope contextString
+	Fpfss         contextString
 }
 
 // CtxKeys is context value keys
 var CtxKeys = ctxKeys{
-	UserID:      "userID",
-	Log:         "Log",
-	RequestID:   "requestID",
-	RequestType: "requestType",
-	Scope:       "scope",
-	Fpfss:       "fpfss",
+	UserID:        "userID",
+	SessionID:     "sessionID",
+	Log:           "Log",
+	RequestID:     "requestID",
+	RequestType:   "requestType",
+	Scope:         "scope",
+	RequiredScope: "requiredScope",
+	Fpfss:         "fpfss",
 }
 
 // UserID extracts userID from context
@@ -36,6 +40,15 @@ func UserID(ctx context.Context) string {
 	return v.(string)
 }
 
+// SessionID extracts the authenticated session's ID from context
+func SessionID(ctx context.Context) int64 {
+	v := ctx.Value(CtxKeys.SessionID)
+	if v == nil {
+		return 0
+	}
+	return v.(int64)
+}
+
 // RequestID extracts requestID from context
 func RequestID(ctx context.Context) string {
 	v := ctx.Value(CtxKeys.RequestID)
@@ -63,6 +76,15 @@ func Scope(ctx context.Context) string {
 	return v.(string)
 }
 
+// RequiredScope extracts the API token scope the route requires from context
+func RequiredScope(ctx context.Context) string {
+	v := ctx.Value(CtxKeys.RequiredScope)
+	if v == nil {
+		return ""
+	}
+	return v.(string)
+}
+
 // LogCtx returns logger with certain context values included
 func LogCtx(ctx context.Context) *logrus.Entry {
 	entry := ctx.Value(CtxKeys.Log).(*logrus.Entry)